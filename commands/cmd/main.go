@@ -20,7 +20,18 @@ var cmds = []commandFunc{
 	commands.Start,
 	commands.Stop,
 	commands.Restart,
+	commands.Resume,
 	commands.Pause,
+	commands.Cooldown,
+	commands.Palette,
+	commands.Rect,
+	commands.Line,
+	commands.Fill,
+	commands.Stamp,
+	commands.Protect,
+	commands.Unprotect,
+	commands.Rollback,
+	commands.Restore,
 }
 
 func createCommands(s *discordgo.Session, guildID string) error {
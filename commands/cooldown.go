@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func Cooldown(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "cooldown",
+		Description:              "Change the cooldown of the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "interval",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Seconds between two pixels, for the role if one is given",
+				MinValue:    utils.Ptr(0.0),
+			},
+			{
+				Name:        "burst",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Pixels that can be placed back to back",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "role",
+				Type:        discordgo.ApplicationCommandOptionRole,
+				Description: "Role to override, leave interval empty to remove the override",
+			},
+		},
+	}, nil
+}
@@ -23,7 +23,7 @@ func Draw(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand,
 				Name:        "color",
 				Required:    true,
 				Type:        discordgo.ApplicationCommandOptionString,
-				Description: "Color in hex format (e.g., #FF5733)",
+				Description: "Palette index or name, hex, rgb(r, g, b) or CSS name (e.g., 3, red, #F53)",
 			},
 		},
 	}, nil
@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+var paletteChoices = []*discordgo.ApplicationCommandOptionChoice{
+	{Name: "r/place 2017 (16 colors)", Value: "16"},
+	{Name: "r/place 2022 (32 colors)", Value: "32"},
+}
+
+func Palette(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "palette",
+		Description:              "Change the colors of the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "preset",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "Predefined palette",
+				Choices:     paletteChoices,
+			},
+			{
+				Name:        "colors",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "Custom palette, e.g. #000000 #FFFFFF #FF4500",
+			},
+		},
+	}, nil
+}
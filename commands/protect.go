@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func Protect(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "protect",
+		Description:              "Lock a region of the current canvas, only admins and moderators can draw in it",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "name",
+				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "Region name, an existing region with that name is replaced",
+				MaxLength:   32,
+			},
+			coordinateOption("x0", "X of a corner"),
+			coordinateOption("y0", "Y of a corner"),
+			coordinateOption("x1", "X of the opposite corner"),
+			coordinateOption("y1", "Y of the opposite corner"),
+			{
+				Name:        "expires_in",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Hours until the region unlocks (default: never)",
+				MinValue:    utils.Ptr(1.0),
+			},
+		},
+	}, nil
+}
+
+func Unprotect(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "unprotect",
+		Description:              "Unlock a region of the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "name",
+				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "Region name",
+			},
+		},
+	}, nil
+}
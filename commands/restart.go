@@ -8,7 +8,7 @@ import (
 func Restart(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
 	return &discordgo.ApplicationCommand{
 		Name:                     "restart",
-		Description:              "Restart on the current canvas",
+		Description:              "Restart the paused canvas, keeping its pixels",
 		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
 		Options:                  []*discordgo.ApplicationCommandOption{},
 	}, nil
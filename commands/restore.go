@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func Restore(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "restore",
+		Description:              "Restore a region of the current canvas to an earlier state",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			coordinateOption("x0", "X of a corner"),
+			coordinateOption("y0", "Y of a corner"),
+			coordinateOption("x1", "X of the opposite corner"),
+			coordinateOption("y1", "Y of the opposite corner"),
+			{
+				Name:        "at",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "Time to restore, as a Unix timestamp or a date like 2025-11-28T20:00:00Z",
+			},
+			{
+				Name:        "minutes_ago",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Restore how the region looked this many minutes ago",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "snapshot",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Description: "Restore from the last /snap instead",
+			},
+		},
+	}, nil
+}
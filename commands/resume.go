@@ -0,0 +1,15 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func Resume(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "resume",
+		Description:              "Resume the paused canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options:                  []*discordgo.ApplicationCommandOption{},
+	}, nil
+}
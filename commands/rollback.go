@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func Rollback(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "rollback",
+		Description:              "Ban a user from the current canvas and revert their pixels",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionManageMessages)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "user",
+				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Description: "User to ban",
+			},
+			{
+				Name:        "hours",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Revert the pixels placed in the last hours (default: 24)",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "dry_run",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Description: "Only preview the canvas after the rollback (default: true)",
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func coordinateOption(name, description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Name:        name,
+		Required:    true,
+		Type:        discordgo.ApplicationCommandOptionInteger,
+		Description: description,
+		MinValue:    utils.Ptr(0.0),
+	}
+}
+
+var shapeColorOption = &discordgo.ApplicationCommandOption{
+	Name:        "color",
+	Required:    true,
+	Type:        discordgo.ApplicationCommandOptionString,
+	Description: "Palette index or name, hex, rgb(r, g, b) or CSS name (e.g., 3, red, #F53)",
+}
+
+func Rect(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "rect",
+		Description:              "Fill a rectangle of the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			coordinateOption("x0", "X of a corner"),
+			coordinateOption("y0", "Y of a corner"),
+			coordinateOption("x1", "X of the opposite corner"),
+			coordinateOption("y1", "Y of the opposite corner"),
+			shapeColorOption,
+		},
+	}, nil
+}
+
+func Line(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "line",
+		Description:              "Draw a line on the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			coordinateOption("x0", "X of the start"),
+			coordinateOption("y0", "Y of the start"),
+			coordinateOption("x1", "X of the end"),
+			coordinateOption("y1", "Y of the end"),
+			shapeColorOption,
+		},
+	}, nil
+}
+
+func Fill(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "fill",
+		Description:              "Flood fill the region around a pixel of the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			coordinateOption("x0", "X of a pixel in the region"),
+			coordinateOption("y0", "Y of a pixel in the region"),
+			shapeColorOption,
+		},
+	}, nil
+}
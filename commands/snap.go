@@ -1,11 +1,107 @@
 package commands
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
 
 func Snap(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
 	return &discordgo.ApplicationCommand{
 		Name:        "snap",
 		Description: "Take a snapshot of the current canvas",
-		Options:     []*discordgo.ApplicationCommandOption{},
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "mode",
+				Required:    false,
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "What to render (default: image)",
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "image", Value: "image"},
+					{Name: "timelapse", Value: "timelapse"},
+					{Name: "heatmap (moderators)", Value: "heatmap"},
+					{Name: "attribution", Value: "attribution"},
+				},
+			},
+			{
+				Name:        "user",
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Description: "Only show the pixels this user still has on the canvas",
+			},
+			{
+				Name:        "heat",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "What the heatmap shows (default: changes)",
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "changes", Value: "changes"},
+					{Name: "recency", Value: "recency"},
+				},
+			},
+			{
+				Name:        "regions",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Description: "Outline the protected regions (default: false)",
+			},
+			{
+				Name:        "x",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Left edge of the region to show (default: 0)",
+				MinValue:    utils.Ptr(0.0),
+			},
+			{
+				Name:        "y",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Top edge of the region to show (default: 0)",
+				MinValue:    utils.Ptr(0.0),
+			},
+			{
+				Name:        "width",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Width of the region to show (default: up to the canvas edge)",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "height",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Height of the region to show (default: up to the canvas edge)",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "zoom",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Screen pixels per canvas pixel (default: fit in 1024 pixels)",
+				MinValue:    utils.Ptr(1.0),
+				MaxValue:    64,
+			},
+			{
+				Name:        "grid",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Description: "Draw a line between pixels, from zoom 4 (default: false)",
+			},
+			{
+				Name:        "rulers",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Description: "Label the coordinates along the edges (default: false)",
+			},
+			{
+				Name:        "frame_interval",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Timelapse: seconds of canvas time per frame (default: 60)",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "max_frames",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Timelapse: most frames in the GIF, longer sessions are sped up (default: 120)",
+				MinValue:    utils.Ptr(1.0),
+				MaxValue:    500,
+			},
+			{
+				Name:        "scale",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Timelapse: GIF pixels per canvas pixel (default: fit in 512 pixels)",
+				MinValue:    utils.Ptr(1.0),
+				MaxValue:    16,
+			},
+		},
 	}, nil
 }
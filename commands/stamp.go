@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+func Stamp(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand, error) {
+	return &discordgo.ApplicationCommand{
+		Name:                     "stamp",
+		Description:              "Import an image on the current canvas",
+		DefaultMemberPermissions: utils.Ptr(int64(discordgo.PermissionAdministrator)),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Name:        "image",
+				Required:    true,
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Description: "PNG, JPEG or GIF image",
+			},
+			coordinateOption("x", "X of the top left corner"),
+			coordinateOption("y", "Y of the top left corner"),
+			{
+				Name:        "width",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Width in pixels, the image keeps its size by default and always fits in the canvas",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "dither",
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Description: "Dither the colors missing from the palette (default: false)",
+			},
+		},
+	}, nil
+}
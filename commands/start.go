@@ -23,6 +23,36 @@ func Start(s *discordgo.Session, guildID string) (*discordgo.ApplicationCommand,
 				Type:        discordgo.ApplicationCommandOptionInteger,
 				Description: "Height of the canvas",
 			},
+			{
+				Name:        "starts_in",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Minutes before the canvas opens (default: now)",
+				MinValue:    utils.Ptr(0.0),
+			},
+			{
+				Name:        "duration",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Hours the canvas stays open (default: 24)",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "cooldown",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Seconds between two pixels (default: 35)",
+				MinValue:    utils.Ptr(0.0),
+			},
+			{
+				Name:        "burst",
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Description: "Pixels that can be placed back to back (default: 1)",
+				MinValue:    utils.Ptr(1.0),
+			},
+			{
+				Name:        "palette",
+				Type:        discordgo.ApplicationCommandOptionString,
+				Description: "Colors players can use (default: 32)",
+				Choices:     paletteChoices,
+			},
 		},
 	}, nil
 }
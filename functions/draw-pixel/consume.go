@@ -0,0 +1,71 @@
+package draw
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// consume is the body shared by the entrypoints of this package. It decodes
+// the input of the Pub/Sub message, rejects it when incomplete, applies it
+// once per message ID and edits the interaction with the outcome.
+// entrypoint names the tracer, consumer the span and the deduplication scope.
+func consume[T any](ctx context.Context, e cloudevents.Event, entrypoint, consumer string, incomplete func(T) bool, apply func(context.Context, canvas.Store, T) (string, error)) error {
+	var payload MessagePublishedData
+	if err := e.DataAs(&payload); err != nil {
+		slog.Error("Invalid CloudEvent", "error", err)
+		return events.Invalid(err)
+	}
+
+	msg := payload.Message
+	parentCtx := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Attributes))
+	tracer := otel.Tracer(entrypoint)
+	ctx, span := tracer.Start(parentCtx, consumer)
+	defer span.End()
+
+	slog.Info("Message received", "data", string(msg.Data), "attributes", msg.Attributes)
+
+	var input T
+	if err := json.Unmarshal(msg.Data, &input); err != nil {
+		slog.Error("Invalid JSON", "raw", string(msg.Data))
+		return events.Invalid(err)
+	}
+
+	if incomplete(input) {
+		slog.Error("Missing required fields", "input", input)
+		return events.Invalid(errors.New("missing required fields"))
+	}
+
+	store, err := canvas.NewFirestoreStore(ctx, projectID, databaseName)
+	if err != nil {
+		slog.Error("Firestore init fail", "error", err)
+		return err
+	}
+	defer store.Close()
+
+	var outcome string
+	ran, err := canvas.ProcessOnce(ctx, store, consumer, msg.MessageID, dedupWindow, func() (err error) {
+		outcome, err = apply(ctx, store, input)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if !ran {
+		slog.Warn("Duplicate message skipped", "messageId", msg.MessageID)
+		return nil
+	}
+
+	token := msg.Attributes["discord_interaction_token"]
+	if err := RespondToInteraction(ctx, token, outcome); err != nil {
+		slog.Error("Failed to report outcome", "consumer", consumer, "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package draw
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/discord"
+	"github.com/bwmarrin/discordgo"
+)
+
+// RespondToInteraction replaces the placeholder the proxy answered /draw
+// with. The proxy answer is ephemeral, so the edit stays visible to the
+// player only. Events without a token are not reported.
+func RespondToInteraction(ctx context.Context, interactionToken string, content string) error {
+	if interactionToken == "" {
+		return nil
+	}
+
+	s, err := discord.Session()
+	if err != nil {
+		slog.ErrorContext(ctx, "discord.Session", "error", err)
+		return err
+	}
+	defer s.Close()
+
+	st, err := s.WebhookMessageEdit(s.State.Application.ID, interactionToken, "@original", &discordgo.WebhookEdit{
+		Content: &content,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "WebhookMessageEdit", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Edited interaction response", "message_id", st.ID, "channel_id", st.ChannelID)
+	return nil
+}
@@ -2,17 +2,17 @@ package draw
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"strings"
 	"time"
 
-	"cloud.google.com/go/firestore"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
 )
 
 type PixelInput struct {
@@ -21,14 +21,10 @@ type PixelInput struct {
 	Color    string `json:"color"`
 	AuthorID string `json:"authorId"`
 	CanvasID string `json:"canvasId"`
-}
-
-type Pixel struct {
-	AuthorID  string    `json:"authorId"`
-	Color     string    `json:"color"`
-	UpdatedAt time.Time `json:"updatedAt"`
-	X         int       `json:"x"`
-	Y         int       `json:"y"`
+	// Discord roles of the author, for per-role cooldowns.
+	Roles []string `json:"roles,omitempty"`
+	// Moderators can draw in protected regions.
+	Moderator bool `json:"moderator,omitempty"`
 }
 
 type MessagePublishedData struct {
@@ -38,230 +34,117 @@ type MessagePublishedData struct {
 type PubSubMessage struct {
 	Data       []byte            `json:"data"`
 	Attributes map[string]string `json:"attributes"`
-}
-
-type CanvasSize struct {
-	Width  int `firestore:"width"`
-	Height int `firestore:"height"`
+	MessageID  string            `json:"messageId"`
 }
 
 func init() {
-	functions.CloudEvent("DrawPixel", DrawPixel)
+	functions.CloudEvent("DrawPixel", events.Consumer("draw-pixel", DrawPixel))
 }
 
-func GetCanvasAdminID(ctx context.Context, canvasID string, database string, projectID string) (string, error) {
-	if projectID == "" {
-		return "", errors.New("projectID missing")
-	}
-	if database == "" {
-		return "", errors.New("database missing")
-	}
-	if canvasID == "" {
-		return "", errors.New("canvasID missing")
-	}
-
-	fs, err := firestore.NewClientWithDatabase(ctx, projectID, database)
-	if err != nil {
-		return "", err
-	}
-	defer fs.Close()
-
-	doc, err := fs.Collection("canvases").Doc(canvasID).Get(ctx)
-	if err != nil {
-		return "", err
-	}
-
-	data := doc.Data()
-	admin, ok := data["AdminID"]
-	if !ok {
-		return "", errors.New("AdminID missing")
-	}
-
-	return admin.(string), nil
+func DrawPixel(ctx context.Context, e cloudevents.Event) error {
+	return consume(ctx, e, "DrawPixel", "draw-pixel", func(input PixelInput) bool {
+		return input.CanvasID == "" || input.Color == "" || input.AuthorID == ""
+	}, Draw)
 }
 
-func GetCanvasStatus(ctx context.Context, canvasID string, database string, projectID string) (string, error) {
-	if canvasID == "" {
-		return "", errors.New("canvasID missing")
-	}
-
-	fs, err := firestore.NewClientWithDatabase(ctx, projectID, database)
-	if err != nil {
-		return "", err
-	}
-	defer fs.Close()
-
-	doc, err := fs.Collection("canvases").Doc(canvasID).Get(ctx)
+// Draw places the pixel if the canvas accepts it and returns the outcome
+// to show the player. Errors are only returned when the event should be
+// retried.
+func Draw(ctx context.Context, store canvas.Store, input PixelInput) (string, error) {
+	c, err := store.GetCanvas(ctx, input.CanvasID)
 	if err != nil {
+		slog.Error("Failed canvas fetch", "error", err)
+		if errors.Is(err, canvas.ErrNotFound) {
+			return "There is no canvas in this channel.", nil
+		}
 		return "", err
 	}
 
-	data := doc.Data()
-	statusRaw, ok := data["Status"]
-	if !ok {
-		return "", errors.New("Status missing")
-	}
-
-	status, ok := statusRaw.(string)
-	if !ok {
-		return "", errors.New("Status invalid")
-	}
-
-	return status, nil
-}
-
-func GetCanvasSize(ctx context.Context, collection string, canvasID string, database string, projectID string) (CanvasSize, error) {
-	var size CanvasSize
-
-	fs, err := firestore.NewClientWithDatabase(ctx, projectID, database)
-	if err != nil {
-		return size, err
+	if c.IsBanned(input.AuthorID) {
+		slog.Warn("Pixel from banned author", "authorId", input.AuthorID)
+		return "You are banned from this canvas.", nil
 	}
-	defer fs.Close()
 
-	doc, err := fs.Collection(collection).Doc(canvasID).Get(ctx)
-	if err != nil {
-		return size, err
+	if c.Status != canvas.StatusStart {
+		slog.Warn("Canvas not in START state", "status", c.Status)
+		return notRunningMessage(c), nil
 	}
 
-	err = doc.DataTo(&size)
-	return size, err
-}
-
-func GetTimeLastPixel(ctx context.Context, collection string, authorId string, database string, projectID string) (time.Time, error) {
-	fs, err := firestore.NewClientWithDatabase(ctx, projectID, database)
-	if err != nil {
-		return time.Time{}, err
+	now := time.Now()
+	if !c.OpenAt(now) {
+		slog.Warn("Canvas outside its drawing window", "startDate", c.StartDate, "endDate", c.EndDate)
+		return notRunningMessage(c), nil
 	}
-	defer fs.Close()
 
-	doc, err := fs.Collection(collection).Doc(authorId).Get(ctx)
-	if err != nil {
-		return time.Time{}, err
+	if region, ok := c.LockedRegion(input.X, input.Y, now); ok && !c.CanBypassRegions(input.AuthorID, input.Moderator) {
+		slog.Warn("Pixel in protected region", "region", region.Name, "x", input.X, "y", input.Y)
+		return lockedMessage(input, region), nil
 	}
 
-	val, ok := doc.Data()["updatedAt"]
-	if !ok {
-		return time.Time{}, errors.New("updatedAt missing")
+	if !c.InBounds(input.X, input.Y) {
+		slog.Error("Pixel out of bounds", "input", input, "width", c.Width, "height", c.Height)
+		return fmt.Sprintf("(%d, %d) is out of bounds, the canvas is %dx%d: x goes from 0 to %d and y from 0 to %d.",
+			input.X, input.Y, c.Width, c.Height, c.Width-1, c.Height-1), nil
 	}
 
-	t, ok := val.(time.Time)
+	colorIndex, ok := c.ColorIndex(input.Color)
 	if !ok {
-		return time.Time{}, errors.New("updatedAt invalid")
+		slog.Warn("Color not in canvas palette", "color", input.Color, "palette", c.PaletteColors())
+		return fmt.Sprintf("%s is not in the canvas palette: %s", input.Color, strings.Join(c.PaletteColors(), " ")), nil
 	}
 
-	return t, nil
-}
-
-func SaveLastPixelTime(ctx context.Context, collection string, authorId string, database string, projectID string, t time.Time) error {
-	fs, err := firestore.NewClientWithDatabase(ctx, projectID, database)
-	if err != nil {
-		return err
-	}
-	defer fs.Close()
-
-	_, err = fs.Collection(collection).Doc(authorId).Set(ctx, map[string]interface{}{
-		"updatedAt": t,
-	})
-
-	return err
-}
-
-func DrawPixel(ctx context.Context, e cloudevents.Event) error {
-	var payload MessagePublishedData
-	if err := e.DataAs(&payload); err != nil {
-		slog.Error("Invalid CloudEvent", "error", err)
-		return nil
-	}
-
-	msg := payload.Message
-	parentCtx := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Attributes))
-	tracer := otel.Tracer("DrawPixel")
-	ctx, span := tracer.Start(parentCtx, "draw-pixel")
-	defer span.End()
-
-	slog.Info("Message received", "data", string(msg.Data), "attributes", msg.Attributes)
-
-	var input PixelInput
-	if err := json.Unmarshal(msg.Data, &input); err != nil {
-		slog.Error("Invalid JSON", "raw", string(msg.Data))
-		return nil
-	}
-
-	if input.CanvasID == "" || input.Color == "" || input.AuthorID == "" {
-		slog.Error("Missing required fields", "input", input)
-		return nil
-	}
-
-	status, err := GetCanvasStatus(ctx, input.CanvasID, databaseName, projectID)
-	if err != nil {
-		slog.Error("Failed status fetch", "error", err)
-		return nil
-	}
+	cooldown := c.CooldownFor(input.AuthorID, input.Roles)
 
-	if status != "START" {
-		slog.Warn("Canvas not in START state", "status", status)
-		return nil
+	pixel := canvas.Pixel{
+		AuthorID:   input.AuthorID,
+		Color:      c.PaletteColors()[colorIndex],
+		ColorIndex: colorIndex,
+		UpdatedAt:  now,
+		X:          input.X,
+		Y:          input.Y,
 	}
 
-	adminID, err := GetCanvasAdminID(ctx, input.CanvasID, databaseName, projectID)
-	if err != nil {
-		slog.Error("Failed adminId fetch", "error", err)
-		return nil
-	}
-
-	if input.AuthorID != adminID {
-		last, err := GetTimeLastPixel(ctx, "rate_limits", input.AuthorID, databaseName, projectID)
-		if err == nil {
-			if elapsed := time.Since(last); elapsed < 35*time.Second {
-				slog.Warn("Cooldown not finished", "remaining", 35*time.Second-elapsed)
-				return nil
-			}
+	var cooldownErr *canvas.CooldownError
+	err = store.PlacePixel(ctx, input.CanvasID, pixel, cooldown)
+	if errors.Is(err, canvas.ErrNotRunning) {
+		// Paused or ended since it was fetched above.
+		slog.Warn("Canvas stopped accepting pixels", "canvas", input.CanvasID)
+		if latest, err := store.GetCanvas(ctx, input.CanvasID); err == nil {
+			c = latest
 		}
-	} else {
-		slog.Info("Admin bypass cooldown")
-	}
-
-	size, err := GetCanvasSize(ctx, "canvases", input.CanvasID, databaseName, projectID)
-	if err != nil {
-		slog.Error("Canvas size error", "error", err)
-		return nil
+		return notRunningMessage(c), nil
 	}
-
-	if input.X < 0 || input.Y < 0 || input.X >= size.Width || input.Y >= size.Height {
-		slog.Error("Pixel out of bounds", "input", input)
-		return nil
+	if errors.As(err, &cooldownErr) {
+		slog.Warn("Cooldown not finished", "remaining", cooldownErr.Remaining)
+		seconds := int(math.Ceil(cooldownErr.Remaining.Seconds()))
+		return fmt.Sprintf("Cooldown: you can place another pixel in %d seconds.", seconds), nil
 	}
-
-	fs, err := firestore.NewClientWithDatabase(ctx, projectID, databaseName)
 	if err != nil {
-		slog.Error("Firestore init fail", "error", err)
-		return err
-	}
-	defer fs.Close()
-
-	pixel := Pixel{
-		AuthorID:  input.AuthorID,
-		Color:     input.Color,
-		UpdatedAt: time.Now(),
-		X:         input.X,
-		Y:         input.Y,
+		slog.Error("Pixel write failed", "error", err)
+		return "", err
 	}
 
-	docID := fmt.Sprintf("%d_%d", input.X, input.Y)
-
-	_, err = fs.Collection("canvases").Doc(input.CanvasID).
-		Collection("pixels").Doc(docID).Set(ctx, pixel)
+	slog.Info("Pixel written", "canvas", input.CanvasID, "x", input.X, "y", input.Y)
+	return fmt.Sprintf("Placed %s at (%d, %d).", pixel.Color, pixel.X, pixel.Y), nil
+}
 
-	if err != nil {
-		slog.Error("Pixel write failed", "error", err)
-		return err
+func lockedMessage(input PixelInput, region canvas.Region) string {
+	msg := fmt.Sprintf("(%d, %d) is in the protected region **%s**.", input.X, input.Y, region.Name)
+	if !region.ExpiresAt.IsZero() {
+		msg += fmt.Sprintf(" It unlocks <t:%d:R>.", region.ExpiresAt.Unix())
 	}
+	return msg
+}
 
-	if err := SaveLastPixelTime(ctx, "rate_limits", input.AuthorID, databaseName, projectID, time.Now()); err != nil {
-		slog.Warn("Failed to update rate limit", "error", err)
+func notRunningMessage(c *canvas.Canvas) string {
+	switch {
+	case c.Status == canvas.StatusPause:
+		return fmt.Sprintf("**%s** is paused.", c.Name)
+	case c.Status == canvas.StatusStop:
+		return fmt.Sprintf("**%s** has ended.", c.Name)
+	case c.Status == canvas.StatusScheduled || time.Now().Before(c.StartDate):
+		return fmt.Sprintf("**%s** opens <t:%d:R>.", c.Name, c.StartDate.Unix())
+	default:
+		return fmt.Sprintf("**%s** is not running.", c.Name)
 	}
-
-	slog.Info("Pixel written", "canvas", input.CanvasID, "x", input.X, "y", input.Y)
-	return nil
 }
@@ -0,0 +1,120 @@
+package draw_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	draw "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func newCanvas(t *testing.T, status canvas.CanvasStatus) canvas.Store {
+	t.Helper()
+	store := canvas.NewMemoryStore()
+	err := store.SaveCanvas(context.Background(), &canvas.Canvas{
+		ID:        "c1",
+		AdminID:   "admin",
+		Name:      "Test",
+		Width:     4,
+		Height:    4,
+		Palette:   []string{"#FFFFFF", "#000000"},
+		Status:    status,
+		StartDate: time.Now().Add(-time.Hour),
+		Cooldown:  &canvas.CooldownPolicy{Interval: 60, Burst: 1},
+	})
+	if err != nil {
+		t.Fatalf("SaveCanvas failed: %v", err)
+	}
+	return store
+}
+
+// paint writes color at points as authorID, recording the placements at
+// the given time.
+func paint(t *testing.T, store canvas.Store, authorID, color string, at time.Time, points ...canvas.Point) {
+	t.Helper()
+	pixels := make([]canvas.Pixel, len(points))
+	for i, p := range points {
+		pixels[i] = canvas.Pixel{X: p.X, Y: p.Y, Color: color, AuthorID: authorID, UpdatedAt: at}
+	}
+	if _, err := store.PutPixels(context.Background(), "c1", pixels); err != nil {
+		t.Fatalf("PutPixels failed: %v", err)
+	}
+}
+
+// colors returns the stored color of every pixel.
+func colors(t *testing.T, store canvas.Store) map[canvas.Point]string {
+	t.Helper()
+	pixels, err := store.ListPixels(context.Background(), "c1")
+	if err != nil {
+		t.Fatalf("ListPixels failed: %v", err)
+	}
+	colors := make(map[canvas.Point]string, len(pixels))
+	for _, p := range pixels {
+		if p.Color != canvas.DefaultColor {
+			colors[canvas.Point{X: p.X, Y: p.Y}] = p.Color
+		}
+	}
+	return colors
+}
+
+func TestDraw(t *testing.T) {
+	ctx := context.Background()
+	store := newCanvas(t, canvas.StatusStart)
+
+	outcome, err := draw.Draw(ctx, store, draw.PixelInput{X: 1, Y: 2, Color: "#000000", AuthorID: "user", CanvasID: "c1"})
+	if err != nil {
+		t.Fatalf("Draw failed: %v", err)
+	}
+	if outcome != "Placed #000000 at (1, 2)." {
+		t.Fatalf("got outcome %q", outcome)
+	}
+	pixels, _ := store.ListPixels(ctx, "c1")
+	if len(pixels) != 1 || pixels[0].X != 1 || pixels[0].Y != 2 || pixels[0].AuthorID != "user" {
+		t.Fatalf("got pixels %+v, want (1, 2) by user", pixels)
+	}
+
+	outcome, err = draw.Draw(ctx, store, draw.PixelInput{X: 0, Y: 0, Color: "#000000", AuthorID: "user", CanvasID: "c1"})
+	if err != nil || !strings.HasPrefix(outcome, "Cooldown:") {
+		t.Fatalf("second pixel: got %q, %v, want a cooldown", outcome, err)
+	}
+}
+
+func TestDrawRefused(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		status canvas.CanvasStatus
+		input  draw.PixelInput
+		want   string
+	}{
+		{"paused", canvas.StatusPause, draw.PixelInput{Color: "#000000"}, "**Test** is paused."},
+		{"stopped", canvas.StatusStop, draw.PixelInput{Color: "#000000"}, "**Test** has ended."},
+		{"out of bounds", canvas.StatusStart, draw.PixelInput{X: 4, Color: "#000000"}, "(4, 0) is out of bounds"},
+		{"not in palette", canvas.StatusStart, draw.PixelInput{Color: "#FF0000"}, "#FF0000 is not in the canvas palette"},
+		{"missing canvas", canvas.StatusStart, draw.PixelInput{CanvasID: "missing", Color: "#000000"}, "There is no canvas in this channel."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCanvas(t, tt.status)
+			input := tt.input
+			input.AuthorID = "user"
+			if input.CanvasID == "" {
+				input.CanvasID = "c1"
+			}
+
+			outcome, err := draw.Draw(ctx, store, input)
+			if err != nil {
+				t.Fatalf("Draw failed: %v", err)
+			}
+			if !strings.HasPrefix(outcome, tt.want) {
+				t.Fatalf("got outcome %q, want %q", outcome, tt.want)
+			}
+			if pixels, _ := store.ListPixels(ctx, "c1"); len(pixels) != 0 {
+				t.Fatalf("got %d pixels, want none", len(pixels))
+			}
+		})
+	}
+}
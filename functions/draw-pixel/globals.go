@@ -1,10 +1,22 @@
 package draw
 
-import "os"
+import (
+	"os"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
 
 var projectID string
 var databaseName string
 
+// dedupWindow is how long processed message IDs are remembered.
+var dedupWindow time.Duration
+
+// snapshotBucket holds the snapshots written by the snap function, only
+// restores read it.
+var snapshotBucket string
+
 func init() {
 	projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
 	if projectID == "" {
@@ -15,4 +27,12 @@ func init() {
 	if databaseName == "" {
 		panic("FIRESTORE_DB not set in environment")
 	}
+
+	snapshotBucket = os.Getenv("SNAPSHOT_BUCKET")
+
+	var err error
+	dedupWindow, err = canvas.ParseDedupWindow(os.Getenv("DEDUP_WINDOW"))
+	if err != nil {
+		panic(err)
+	}
 }
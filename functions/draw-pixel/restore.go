@@ -0,0 +1,164 @@
+package draw
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// RestoreInput is published on drawing-restore by /restore. The rectangle
+// with corners (X0, Y0) and (X1, Y1) is brought back to how it looked at
+// At, or in the last snapshot taken by the snap function when Snapshot is
+// set.
+type RestoreInput struct {
+	CanvasID string    `json:"canvasId"`
+	AuthorID string    `json:"authorId"`
+	X0       int       `json:"x0"`
+	Y0       int       `json:"y0"`
+	X1       int       `json:"x1"`
+	Y1       int       `json:"y1"`
+	At       time.Time `json:"at"`
+	Snapshot bool      `json:"snapshot,omitempty"`
+}
+
+func init() {
+	functions.CloudEvent("DrawRestore", events.Consumer("draw-restore", DrawRestore))
+}
+
+func DrawRestore(ctx context.Context, e cloudevents.Event) error {
+	return consume(ctx, e, "DrawRestore", "draw-restore", func(input RestoreInput) bool {
+		return input.CanvasID == "" || input.AuthorID == "" || (input.At.IsZero() && !input.Snapshot)
+	}, Restore)
+}
+
+// Restore repairs the rectangle for the canvas admin, leaving the rest of
+// the canvas untouched. Errors are only returned when the event should be
+// retried.
+func Restore(ctx context.Context, store canvas.Store, input RestoreInput) (string, error) {
+	c, err := store.GetCanvas(ctx, input.CanvasID)
+	if err != nil {
+		slog.Error("Failed canvas fetch", "error", err)
+		if errors.Is(err, canvas.ErrNotFound) {
+			return "There is no canvas in this channel.", nil
+		}
+		return "", err
+	}
+
+	if _, err := canvas.Transition(c, canvas.ActionEdit); err != nil {
+		slog.Warn("Canvas cannot be edited", "status", c.Status)
+		return notRunningMessage(c), nil
+	}
+
+	if input.AuthorID != c.AdminID {
+		slog.Warn("Restore from non-admin", "authorId", input.AuthorID, "adminId", c.AdminID)
+		return "Only the canvas admin can restore regions.", nil
+	}
+
+	if !c.InBounds(input.X0, input.Y0) || !c.InBounds(input.X1, input.Y1) {
+		slog.Warn("Restore out of bounds", "input", input, "width", c.Width, "height", c.Height)
+		return fmt.Sprintf("The region goes out of bounds, the canvas is %dx%d: x goes from 0 to %d and y from 0 to %d.",
+			c.Width, c.Height, c.Width-1, c.Height-1), nil
+	}
+
+	var colors map[canvas.Point]string
+	source := "the last snapshot"
+	if input.Snapshot {
+		snapshot, takenAt, err := readSnapshot(ctx, c.ID)
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return "There is no snapshot of this canvas yet, take one with /snap.", nil
+		}
+		if err != nil {
+			slog.Error("Failed snapshot read", "error", err)
+			return "", err
+		}
+		if takenAt.Before(c.ResetAt) {
+			// Taken in an earlier session of the canvas.
+			slog.Warn("Snapshot older than the last reset", "takenAt", takenAt, "resetAt", c.ResetAt)
+			return "The last snapshot was taken before the canvas was reset, take a new one with /snap.", nil
+		}
+
+		colors = make(map[canvas.Point]string, len(snapshot))
+		for _, pixel := range snapshot {
+			colors[canvas.Point{X: pixel.X, Y: pixel.Y}] = pixel.Color
+		}
+	} else {
+		if input.At.After(time.Now()) {
+			return "Cannot restore from the future.", nil
+		}
+		if input.At.Before(c.ResetAt) {
+			return fmt.Sprintf("Cannot restore from before the canvas was reset <t:%d:R>.", c.ResetAt.Unix()), nil
+		}
+		source = fmt.Sprintf("<t:%d:f>", input.At.Unix())
+
+		current, err := store.ListPixels(ctx, input.CanvasID)
+		if err != nil {
+			slog.Error("Failed pixels fetch", "error", err)
+			return "", err
+		}
+		placements, err := store.HistoryBetween(ctx, input.CanvasID, input.At, time.Now())
+		if err != nil {
+			slog.Error("Failed history fetch", "error", err)
+			return "", err
+		}
+		colors = canvas.ColorsAt(current, placements)
+	}
+
+	pixels := c.RestorePixels(colors, input.X0, input.Y0, input.X1, input.Y1)
+	now := time.Now()
+	for i := range pixels {
+		pixels[i].AuthorID = input.AuthorID
+		pixels[i].UpdatedAt = now
+	}
+
+	changed, err := store.PutPixels(ctx, input.CanvasID, pixels)
+	if err != nil {
+		slog.Error("Restore write failed", "error", err, "changed", changed)
+		return "", err
+	}
+
+	slog.Info("Restore written", "canvas", input.CanvasID, "snapshot", input.Snapshot, "at", input.At, "changed", changed)
+	return fmt.Sprintf("Restored (%d, %d) to (%d, %d) from %s, %d pixels changed.",
+		min(input.X0, input.X1), min(input.Y0, input.Y1), max(input.X0, input.X1), max(input.Y0, input.Y1), source, changed), nil
+}
+
+// readSnapshot reads the canvas_<id>.json.gz pixels written by the snap
+// function and when they were written.
+func readSnapshot(ctx context.Context, canvasID string) ([]canvas.Pixel, time.Time, error) {
+	if snapshotBucket == "" {
+		return nil, time.Time{}, errors.New("SNAPSHOT_BUCKET not set in environment")
+	}
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("storage.NewClient: %w", err)
+	}
+	defer client.Close()
+
+	r, err := client.Bucket(snapshotBucket).Object(fmt.Sprintf("canvas_%s.json.gz", canvasID)).NewReader(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer gz.Close()
+
+	var pixels []canvas.Pixel
+	if err := json.NewDecoder(gz).Decode(&pixels); err != nil {
+		return nil, time.Time{}, fmt.Errorf("snapshot of %s: %w", canvasID, err)
+	}
+	return pixels, r.Attrs.LastModified, nil
+}
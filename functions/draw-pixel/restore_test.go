@@ -0,0 +1,78 @@
+package draw_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	draw "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	store := newCanvas(t, canvas.StatusStart)
+	now := time.Now()
+
+	paint(t, store, "user", "#000000", now.Add(-40*time.Minute), canvas.Point{X: 0, Y: 0})
+	paint(t, store, "vandal", "#FFFFFF", now.Add(-10*time.Minute), canvas.Point{X: 0, Y: 0})
+	paint(t, store, "vandal", "#000000", now.Add(-10*time.Minute), canvas.Point{X: 1, Y: 1}, canvas.Point{X: 3, Y: 3})
+
+	at := now.Add(-20 * time.Minute)
+	input := draw.RestoreInput{CanvasID: "c1", AuthorID: "admin", X0: 1, Y0: 1, X1: 0, Y1: 0, At: at}
+	outcome, err := draw.Restore(ctx, store, input)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if want := fmt.Sprintf("Restored (0, 0) to (1, 1) from <t:%d:f>, 2 pixels changed.", at.Unix()); outcome != want {
+		t.Fatalf("got outcome %q, want %q", outcome, want)
+	}
+
+	// Outside the rectangle, the vandal's pixel stays.
+	got := colors(t, store)
+	if len(got) != 2 || got[canvas.Point{X: 0, Y: 0}] != "#000000" || got[canvas.Point{X: 3, Y: 3}] != "#000000" {
+		t.Fatalf("got pixels %v, want (0, 0) and (3, 3)", got)
+	}
+}
+
+func TestRestoreRefused(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		status canvas.CanvasStatus
+		input  draw.RestoreInput
+		want   string
+	}{
+		{"non-admin", canvas.StatusStart, draw.RestoreInput{AuthorID: "user", At: now.Add(-time.Minute)}, "Only the canvas admin can restore regions."},
+		{"stopped", canvas.StatusStop, draw.RestoreInput{At: now.Add(-time.Minute)}, "**Test** has ended."},
+		{"out of bounds", canvas.StatusStart, draw.RestoreInput{X1: 4, At: now.Add(-time.Minute)}, "The region goes out of bounds, the canvas is 4x4"},
+		{"future", canvas.StatusStart, draw.RestoreInput{At: now.Add(time.Hour)}, "Cannot restore from the future."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCanvas(t, tt.status)
+			paint(t, store, "vandal", "#000000", now.Add(-time.Second), canvas.Point{X: 0, Y: 0})
+
+			input := tt.input
+			input.CanvasID = "c1"
+			if input.AuthorID == "" {
+				input.AuthorID = "admin"
+			}
+
+			outcome, err := draw.Restore(ctx, store, input)
+			if err != nil {
+				t.Fatalf("Restore failed: %v", err)
+			}
+			if !strings.HasPrefix(outcome, tt.want) {
+				t.Fatalf("got outcome %q, want %q", outcome, tt.want)
+			}
+			if got := colors(t, store); len(got) != 1 {
+				t.Fatalf("got %d pixels, want the vandal's one", len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// RollbackInput is published on drawing-rollback by /rollback once the
+// moderator turns the dry run off. The placements TargetID made in
+// [From, To) are reverted. /rollback dry runs go to the snap function.
+type RollbackInput struct {
+	CanvasID  string    `json:"canvasId"`
+	AuthorID  string    `json:"authorId"`
+	Moderator bool      `json:"moderator,omitempty"`
+	TargetID  string    `json:"targetId"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+func init() {
+	functions.CloudEvent("DrawRollback", events.Consumer("draw-rollback", DrawRollback))
+}
+
+func DrawRollback(ctx context.Context, e cloudevents.Event) error {
+	return consume(ctx, e, "DrawRollback", "draw-rollback", func(input RollbackInput) bool {
+		return input.CanvasID == "" || input.AuthorID == "" || input.TargetID == ""
+	}, Rollback)
+}
+
+// Rollback bans the target from the canvas and reverts their placements.
+// Errors are only returned when the event should be retried, which is safe
+// as both steps can be replayed.
+func Rollback(ctx context.Context, store canvas.Store, input RollbackInput) (string, error) {
+	c, err := store.GetCanvas(ctx, input.CanvasID)
+	if err != nil {
+		slog.Error("Failed canvas fetch", "error", err)
+		if errors.Is(err, canvas.ErrNotFound) {
+			return "There is no canvas in this channel.", nil
+		}
+		return "", err
+	}
+
+	if _, err := canvas.Transition(c, canvas.ActionEdit); err != nil {
+		slog.Warn("Canvas cannot be edited", "status", c.Status)
+		return notRunningMessage(c), nil
+	}
+
+	if !input.Moderator && input.AuthorID != c.AdminID {
+		slog.Warn("Rollback from non-moderator", "authorId", input.AuthorID)
+		return "Only moderators can roll back pixels.", nil
+	}
+
+	if err := store.BanAuthor(ctx, input.CanvasID, input.TargetID); err != nil {
+		slog.Error("Ban failed", "error", err)
+		return "", err
+	}
+
+	placements, err := store.HistoryBetween(ctx, input.CanvasID, input.From, time.Now())
+	if err != nil {
+		slog.Error("Failed history fetch", "error", err)
+		return "", err
+	}
+
+	pixels := c.RollbackPixels(placements, input.TargetID, input.From, input.To)
+	now := time.Now()
+	for i := range pixels {
+		pixels[i].AuthorID = input.AuthorID
+		pixels[i].UpdatedAt = now
+	}
+
+	changed, err := store.PutPixels(ctx, input.CanvasID, pixels)
+	if err != nil {
+		slog.Error("Rollback write failed", "error", err, "changed", changed)
+		return "", err
+	}
+
+	slog.Info("Rollback written", "canvas", input.CanvasID, "target", input.TargetID, "changed", changed)
+	return fmt.Sprintf("Banned <@%s> and reverted %d of their pixels.", input.TargetID, changed), nil
+}
@@ -0,0 +1,87 @@
+package draw_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	draw "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	store := newCanvas(t, canvas.StatusStart)
+	now := time.Now()
+
+	paint(t, store, "user", "#000000", now.Add(-40*time.Minute), canvas.Point{X: 3, Y: 3})
+	paint(t, store, "vandal", "#000000", now.Add(-20*time.Minute), canvas.Point{X: 0, Y: 0}, canvas.Point{X: 1, Y: 0})
+	// Painted over the vandal, kept by the rollback.
+	paint(t, store, "user", "#FFFFFF", now.Add(-10*time.Minute), canvas.Point{X: 1, Y: 0})
+
+	input := draw.RollbackInput{CanvasID: "c1", AuthorID: "mod", Moderator: true, TargetID: "vandal", From: now.Add(-30 * time.Minute), To: now}
+	outcome, err := draw.Rollback(ctx, store, input)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if outcome != "Banned <@vandal> and reverted 1 of their pixels." {
+		t.Fatalf("got outcome %q", outcome)
+	}
+
+	got := colors(t, store)
+	if len(got) != 1 || got[canvas.Point{X: 3, Y: 3}] != "#000000" {
+		t.Fatalf("got pixels %v, want only (3, 3)", got)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); !c.IsBanned("vandal") {
+		t.Fatalf("vandal not banned: %v", c.Banned)
+	}
+
+	// Replaying the rollback changes nothing more.
+	outcome, err = draw.Rollback(ctx, store, input)
+	if err != nil || outcome != "Banned <@vandal> and reverted 0 of their pixels." {
+		t.Fatalf("replayed rollback: got %q, %v", outcome, err)
+	}
+}
+
+func TestRollbackRefused(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		status canvas.CanvasStatus
+		input  draw.RollbackInput
+		want   string
+	}{
+		{"non-moderator", canvas.StatusStart, draw.RollbackInput{AuthorID: "user"}, "Only moderators can roll back pixels."},
+		{"stopped", canvas.StatusStop, draw.RollbackInput{AuthorID: "admin"}, "**Test** has ended."},
+		{"missing canvas", canvas.StatusStart, draw.RollbackInput{CanvasID: "missing", AuthorID: "admin"}, "There is no canvas in this channel."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCanvas(t, tt.status)
+			paint(t, store, "vandal", "#000000", now.Add(-time.Minute), canvas.Point{X: 0, Y: 0})
+
+			input := tt.input
+			input.TargetID = "vandal"
+			input.From, input.To = now.Add(-time.Hour), now
+			if input.CanvasID == "" {
+				input.CanvasID = "c1"
+			}
+
+			outcome, err := draw.Rollback(ctx, store, input)
+			if err != nil {
+				t.Fatalf("Rollback failed: %v", err)
+			}
+			if outcome != tt.want {
+				t.Fatalf("got outcome %q, want %q", outcome, tt.want)
+			}
+			if got := colors(t, store); len(got) != 1 {
+				t.Fatalf("got %d pixels, want the vandal's one", len(got))
+			}
+			if c, _ := store.GetCanvas(ctx, "c1"); c.IsBanned("vandal") {
+				t.Fatal("vandal banned by a refused rollback")
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package draw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// ShapeInput is published on drawing-shape by the admin drawing commands.
+// (X0, Y0) is the start of a line, a corner of a rectangle or the seed of a
+// fill, (X1, Y1) the other end.
+type ShapeInput struct {
+	Shape    canvas.Shape `json:"shape"`
+	X0       int          `json:"x0"`
+	Y0       int          `json:"y0"`
+	X1       int          `json:"x1,omitempty"`
+	Y1       int          `json:"y1,omitempty"`
+	Color    string       `json:"color"`
+	AuthorID string       `json:"authorId"`
+	CanvasID string       `json:"canvasId"`
+}
+
+func init() {
+	functions.CloudEvent("DrawShape", events.Consumer("draw-shape", DrawShape))
+}
+
+func DrawShape(ctx context.Context, e cloudevents.Event) error {
+	return consume(ctx, e, "DrawShape", "draw-shape", func(input ShapeInput) bool {
+		return input.CanvasID == "" || input.Color == "" || input.AuthorID == "" || input.Shape == ""
+	}, DrawShapeOn)
+}
+
+// DrawShapeOn writes the shape on the canvas for its admin and returns the
+// outcome to show them. Errors are only returned when the event should be
+// retried.
+func DrawShapeOn(ctx context.Context, store canvas.Store, input ShapeInput) (string, error) {
+	c, err := store.GetCanvas(ctx, input.CanvasID)
+	if err != nil {
+		slog.Error("Failed canvas fetch", "error", err)
+		if errors.Is(err, canvas.ErrNotFound) {
+			return "There is no canvas in this channel.", nil
+		}
+		return "", err
+	}
+
+	if _, err := canvas.Transition(c, canvas.ActionEdit); err != nil {
+		slog.Warn("Canvas cannot be edited", "status", c.Status)
+		return notRunningMessage(c), nil
+	}
+
+	if input.AuthorID != c.AdminID {
+		slog.Warn("Shape from non-admin", "authorId", input.AuthorID, "adminId", c.AdminID)
+		return "Only the canvas admin can draw shapes.", nil
+	}
+
+	if !c.InBounds(input.X0, input.Y0) || (input.Shape != canvas.ShapeFill && !c.InBounds(input.X1, input.Y1)) {
+		slog.Warn("Shape out of bounds", "input", input, "width", c.Width, "height", c.Height)
+		return fmt.Sprintf("The shape goes out of bounds, the canvas is %dx%d: x goes from 0 to %d and y from 0 to %d.",
+			c.Width, c.Height, c.Width-1, c.Height-1), nil
+	}
+
+	colorIndex, ok := c.ColorIndex(input.Color)
+	if !ok {
+		slog.Warn("Color not in canvas palette", "color", input.Color, "palette", c.PaletteColors())
+		return fmt.Sprintf("%s is not in the canvas palette: %s", input.Color, strings.Join(c.PaletteColors(), " ")), nil
+	}
+
+	var points []canvas.Point
+	switch input.Shape {
+	case canvas.ShapeRect:
+		points = canvas.Rect(input.X0, input.Y0, input.X1, input.Y1)
+	case canvas.ShapeLine:
+		points = canvas.Line(input.X0, input.Y0, input.X1, input.Y1)
+	case canvas.ShapeFill:
+		stored, err := store.ListPixels(ctx, input.CanvasID)
+		if err != nil {
+			slog.Error("Failed pixels fetch", "error", err)
+			return "", err
+		}
+		points = c.FloodFill(stored, input.X0, input.Y0)
+	default:
+		slog.Error("Unknown shape", "shape", input.Shape)
+		return fmt.Sprintf("Unknown shape %q.", input.Shape), nil
+	}
+
+	now := time.Now()
+	pixels := make([]canvas.Pixel, len(points))
+	for i, p := range points {
+		pixels[i] = canvas.Pixel{
+			AuthorID:   input.AuthorID,
+			Color:      c.PaletteColors()[colorIndex],
+			ColorIndex: colorIndex,
+			UpdatedAt:  now,
+			X:          p.X,
+			Y:          p.Y,
+		}
+	}
+
+	changed, err := store.PutPixels(ctx, input.CanvasID, pixels)
+	if err != nil {
+		slog.Error("Shape write failed", "error", err, "changed", changed)
+		return "", err
+	}
+
+	slog.Info("Shape written", "canvas", input.CanvasID, "shape", input.Shape, "points", len(points), "changed", changed)
+	return fmt.Sprintf("Drew the %s in %s, %d pixels changed.", shapeNames[input.Shape], pixels[0].Color, changed), nil
+}
+
+var shapeNames = map[canvas.Shape]string{
+	canvas.ShapeRect: "rectangle",
+	canvas.ShapeLine: "line",
+	canvas.ShapeFill: "fill",
+}
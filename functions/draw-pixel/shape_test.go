@@ -0,0 +1,82 @@
+package draw_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	draw "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestDrawShape(t *testing.T) {
+	ctx := context.Background()
+	store := newCanvas(t, canvas.StatusStart)
+
+	rect := draw.ShapeInput{Shape: canvas.ShapeRect, X0: 1, Y0: 1, X1: 0, Y1: 0, Color: "#000000", AuthorID: "admin", CanvasID: "c1"}
+	outcome, err := draw.DrawShapeOn(ctx, store, rect)
+	if err != nil {
+		t.Fatalf("DrawShapeOn failed: %v", err)
+	}
+	if outcome != "Drew the rectangle in #000000, 4 pixels changed." {
+		t.Fatalf("got outcome %q", outcome)
+	}
+	if got := colors(t, store); len(got) != 4 {
+		t.Fatalf("got %d black pixels, want 4", len(got))
+	}
+
+	// Pixels already in the color are not counted.
+	line := draw.ShapeInput{Shape: canvas.ShapeLine, X0: 0, Y0: 1, X1: 3, Y1: 1, Color: "#000000", AuthorID: "admin", CanvasID: "c1"}
+	outcome, err = draw.DrawShapeOn(ctx, store, line)
+	if err != nil || outcome != "Drew the line in #000000, 2 pixels changed." {
+		t.Fatalf("line: got %q, %v", outcome, err)
+	}
+
+	// (2, 0) and (3, 0) are cut off by the line.
+	fill := draw.ShapeInput{Shape: canvas.ShapeFill, X0: 3, Y0: 3, Color: "#000000", AuthorID: "admin", CanvasID: "c1"}
+	outcome, err = draw.DrawShapeOn(ctx, store, fill)
+	if err != nil || outcome != "Drew the fill in #000000, 8 pixels changed." {
+		t.Fatalf("fill: got %q, %v", outcome, err)
+	}
+}
+
+func TestDrawShapeRefused(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		status canvas.CanvasStatus
+		input  draw.ShapeInput
+		want   string
+	}{
+		{"non-admin", canvas.StatusStart, draw.ShapeInput{Shape: canvas.ShapeRect, AuthorID: "user"}, "Only the canvas admin can draw shapes."},
+		{"stopped", canvas.StatusStop, draw.ShapeInput{Shape: canvas.ShapeRect}, "**Test** has ended."},
+		{"out of bounds", canvas.StatusStart, draw.ShapeInput{Shape: canvas.ShapeLine, X1: 4}, "The shape goes out of bounds, the canvas is 4x4"},
+		{"not in palette", canvas.StatusStart, draw.ShapeInput{Shape: canvas.ShapeRect, Color: "#FF0000"}, "#FF0000 is not in the canvas palette"},
+		{"unknown shape", canvas.StatusStart, draw.ShapeInput{Shape: "circle"}, `Unknown shape "circle".`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCanvas(t, tt.status)
+			input := tt.input
+			input.CanvasID = "c1"
+			if input.AuthorID == "" {
+				input.AuthorID = "admin"
+			}
+			if input.Color == "" {
+				input.Color = "#000000"
+			}
+
+			outcome, err := draw.DrawShapeOn(ctx, store, input)
+			if err != nil {
+				t.Fatalf("DrawShapeOn failed: %v", err)
+			}
+			if !strings.HasPrefix(outcome, tt.want) {
+				t.Fatalf("got outcome %q, want %q", outcome, tt.want)
+			}
+			if got := colors(t, store); len(got) != 0 {
+				t.Fatalf("got %d pixels, want none", len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package draw
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// maxStampBytes bounds the attachments downloaded for a stamp.
+const maxStampBytes = 8 << 20
+
+// StampInput is published on drawing-stamp by /stamp. ImageURL points to
+// the Discord attachment, Width is the stamp width in canvas pixels, zero
+// to keep the image size.
+type StampInput struct {
+	ImageURL string `json:"imageUrl"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Width    int    `json:"width,omitempty"`
+	Dither   bool   `json:"dither,omitempty"`
+	AuthorID string `json:"authorId"`
+	CanvasID string `json:"canvasId"`
+}
+
+func init() {
+	functions.CloudEvent("DrawStamp", events.Consumer("draw-stamp", DrawStamp))
+}
+
+func DrawStamp(ctx context.Context, e cloudevents.Event) error {
+	return consume(ctx, e, "DrawStamp", "draw-stamp", func(input StampInput) bool {
+		return input.CanvasID == "" || input.ImageURL == "" || input.AuthorID == ""
+	}, Stamp)
+}
+
+// Stamp imports the image on the canvas for its admin and returns the
+// outcome to show them. Errors are only returned when the event should be
+// retried.
+func Stamp(ctx context.Context, store canvas.Store, input StampInput) (string, error) {
+	c, err := store.GetCanvas(ctx, input.CanvasID)
+	if err != nil {
+		slog.Error("Failed canvas fetch", "error", err)
+		if errors.Is(err, canvas.ErrNotFound) {
+			return "There is no canvas in this channel.", nil
+		}
+		return "", err
+	}
+
+	if _, err := canvas.Transition(c, canvas.ActionEdit); err != nil {
+		slog.Warn("Canvas cannot be edited", "status", c.Status)
+		return notRunningMessage(c), nil
+	}
+
+	if input.AuthorID != c.AdminID {
+		slog.Warn("Stamp from non-admin", "authorId", input.AuthorID, "adminId", c.AdminID)
+		return "Only the canvas admin can stamp images.", nil
+	}
+
+	if !c.InBounds(input.X, input.Y) {
+		slog.Warn("Stamp out of bounds", "input", input, "width", c.Width, "height", c.Height)
+		return fmt.Sprintf("(%d, %d) is out of bounds, the canvas is %dx%d: x goes from 0 to %d and y from 0 to %d.",
+			input.X, input.Y, c.Width, c.Height, c.Width-1, c.Height-1), nil
+	}
+
+	img, err := fetchImage(ctx, input.ImageURL)
+	if err != nil {
+		slog.Warn("Failed to read stamp image", "url", input.ImageURL, "error", err)
+		return fmt.Sprintf("Could not read the image: %s.", err), nil
+	}
+
+	pixels, err := c.StampPixels(img, input.X, input.Y, input.Width, input.Dither)
+	if err != nil {
+		slog.Warn("Failed to convert stamp image", "error", err)
+		return fmt.Sprintf("Could not stamp the image: %s.", err), nil
+	}
+
+	now := time.Now()
+	for i := range pixels {
+		pixels[i].AuthorID = input.AuthorID
+		pixels[i].UpdatedAt = now
+	}
+
+	changed, err := store.PutPixels(ctx, input.CanvasID, pixels)
+	if err != nil {
+		slog.Error("Stamp write failed", "error", err, "changed", changed)
+		return "", err
+	}
+
+	slog.Info("Stamp written", "canvas", input.CanvasID, "x", input.X, "y", input.Y, "pixels", len(pixels), "changed", changed)
+	return fmt.Sprintf("Stamped the image at (%d, %d), %d pixels changed.", input.X, input.Y, changed), nil
+}
+
+// fetchImage downloads and decodes a PNG, JPEG or GIF image. Only the first
+// frame of a GIF is kept.
+func fetchImage(ctx context.Context, url string) (image.Image, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxStampBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxStampBytes {
+		return nil, fmt.Errorf("the image is larger than %d MiB", maxStampBytes>>20)
+	}
+
+	// Checking the size first avoids decoding huge images.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("the attachment is not a PNG, JPEG or GIF image")
+	}
+	if config.Width > canvas.MaxStampSide || config.Height > canvas.MaxStampSide {
+		return nil, fmt.Errorf("the image is larger than %dx%d", canvas.MaxStampSide, canvas.MaxStampSide)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
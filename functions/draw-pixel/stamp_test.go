@@ -0,0 +1,111 @@
+package draw_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	draw "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+// serveImage serves a black square of side pixels at /stamp.png.
+func serveImage(t *testing.T, side int) *httptest.Server {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := range side {
+		for x := range side {
+			img.Set(x, y, color.Black)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode failed: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stamp.png", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/text.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not an image"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStamp(t *testing.T) {
+	ctx := context.Background()
+	store := newCanvas(t, canvas.StatusStart)
+	server := serveImage(t, 2)
+
+	input := draw.StampInput{ImageURL: server.URL + "/stamp.png", X: 1, Y: 1, AuthorID: "admin", CanvasID: "c1"}
+	outcome, err := draw.Stamp(ctx, store, input)
+	if err != nil {
+		t.Fatalf("Stamp failed: %v", err)
+	}
+	if outcome != "Stamped the image at (1, 1), 4 pixels changed." {
+		t.Fatalf("got outcome %q", outcome)
+	}
+	got := colors(t, store)
+	if len(got) != 4 || got[canvas.Point{X: 2, Y: 2}] != "#000000" {
+		t.Fatalf("got pixels %v, want a black square at (1, 1)", got)
+	}
+
+	// The stamp is clipped to the canvas, and pixels already black are not
+	// counted.
+	input.X, input.Y, input.Width = 2, 2, 4
+	outcome, err = draw.Stamp(ctx, store, input)
+	if err != nil || outcome != "Stamped the image at (2, 2), 3 pixels changed." {
+		t.Fatalf("clipped stamp: got %q, %v", outcome, err)
+	}
+}
+
+func TestStampRefused(t *testing.T) {
+	ctx := context.Background()
+	server := serveImage(t, 2)
+
+	tests := []struct {
+		name   string
+		status canvas.CanvasStatus
+		input  draw.StampInput
+		want   string
+	}{
+		{"non-admin", canvas.StatusStart, draw.StampInput{AuthorID: "user"}, "Only the canvas admin can stamp images."},
+		{"stopped", canvas.StatusStop, draw.StampInput{}, "**Test** has ended."},
+		{"out of bounds", canvas.StatusStart, draw.StampInput{Y: 4}, "(0, 4) is out of bounds"},
+		{"missing image", canvas.StatusStart, draw.StampInput{ImageURL: server.URL + "/missing.png"}, "Could not read the image: download failed with status 404."},
+		{"not an image", canvas.StatusStart, draw.StampInput{ImageURL: server.URL + "/text.txt"}, "Could not read the image: the attachment is not a PNG, JPEG or GIF image."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newCanvas(t, tt.status)
+			input := tt.input
+			input.CanvasID = "c1"
+			if input.AuthorID == "" {
+				input.AuthorID = "admin"
+			}
+			if input.ImageURL == "" {
+				input.ImageURL = server.URL + "/stamp.png"
+			}
+
+			outcome, err := draw.Stamp(ctx, store, input)
+			if err != nil {
+				t.Fatalf("Stamp failed: %v", err)
+			}
+			if !strings.HasPrefix(outcome, tt.want) {
+				t.Fatalf("got outcome %q, want %q", outcome, tt.want)
+			}
+			if got := colors(t, store); len(got) != 0 {
+				t.Fatalf("got %d pixels, want none", len(got))
+			}
+		})
+	}
+}
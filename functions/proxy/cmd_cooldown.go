@@ -0,0 +1,104 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/Evan-Lab/cloud-native/lib/go/utils"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("cooldown", cooldownCmd)
+}
+
+type ConfigureData struct {
+	CanvasID string        `json:"canvasId"`
+	AuthorID string        `json:"authorId"`
+	Cooldown *CooldownData `json:"cooldown,omitempty"`
+	Palette  *PaletteData  `json:"palette,omitempty"`
+
+	Protect   *RegionData `json:"protect,omitempty"`
+	Unprotect string      `json:"unprotect,omitempty"`
+}
+
+type CooldownData struct {
+	Interval *int   `json:"interval,omitempty"`
+	Burst    *int   `json:"burst,omitempty"`
+	RoleID   string `json:"roleId,omitempty"`
+}
+
+func cooldownCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.cooldown")
+	defer span.End()
+
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create Pub/Sub client", "error", err)
+		return nil, err
+	}
+
+	publisher := client.Publisher("session-events")
+	defer publisher.Stop()
+
+	cooldown := &CooldownData{}
+	if opt := data.GetOption("interval"); opt != nil {
+		cooldown.Interval = utils.Ptr(int(opt.IntValue()))
+	}
+	if opt := data.GetOption("burst"); opt != nil {
+		cooldown.Burst = utils.Ptr(int(opt.IntValue()))
+	}
+	if opt := data.GetOption("role"); opt != nil {
+		cooldown.RoleID = opt.RoleValue(nil, "").ID
+	}
+
+	payload := ConfigureData{
+		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
+		Cooldown: cooldown,
+	}
+
+	slog.DebugContext(ctx, "Cooldown payload", "payload", payload)
+	span.SetAttributes(
+		attribute.String("cooldown.canvas_id", payload.CanvasID),
+		attribute.String("cooldown.author_id", payload.AuthorID),
+	)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal cooldown payload", "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Cooldown payload", "body", string(body))
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["action"] = "configure"
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish cooldown message", "error", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Published cooldown message", "canvas_id", payload.CanvasID)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Cooldown update received! Applying...",
+		},
+	}, nil
+}
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 
 	"cloud.google.com/go/pubsub/v2"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
 	"github.com/bwmarrin/discordgo"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -18,11 +19,21 @@ func init() {
 }
 
 type DrawData struct {
-	X        int    `json:"x"`
-	Y        int    `json:"y"`
-	Color    string `json:"color"`
-	AuthorID string `json:"authorId"`
-	CanvasID string `json:"canvasId"`
+	X        int      `json:"x"`
+	Y        int      `json:"y"`
+	Color    string   `json:"color"`
+	AuthorID string   `json:"authorId"`
+	CanvasID string   `json:"canvasId"`
+	Roles    []string `json:"roles,omitempty"`
+
+	Moderator bool `json:"moderator,omitempty"`
+}
+
+// moderatorPermissions let a member draw in protected regions.
+const moderatorPermissions = discordgo.PermissionAdministrator | discordgo.PermissionManageMessages
+
+func isModerator(member *discordgo.Member) bool {
+	return member.Permissions&moderatorPermissions != 0
 }
 
 func drawCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
@@ -41,6 +52,9 @@ func drawCmd(ctx context.Context, interaction discordgo.Interaction, data discor
 	payload := DrawData{
 		CanvasID: interaction.GuildID + interaction.ChannelID,
 		AuthorID: interaction.Member.User.ID,
+		Roles:    interaction.Member.Roles,
+
+		Moderator: isModerator(interaction.Member),
 	}
 
 	colorOpt := data.GetOption("color")
@@ -52,7 +66,13 @@ func drawCmd(ctx context.Context, interaction discordgo.Interaction, data discor
 		return nil, fmt.Errorf("missing required options")
 	}
 
-	payload.Color = colorOpt.StringValue()
+	color, err := canvas.NormalizeColor(colorOpt.StringValue())
+	if err != nil {
+		slog.InfoContext(ctx, "Invalid color", "color", colorOpt.StringValue(), "error", err)
+		return unknownColorResponse(colorOpt.StringValue()), nil
+	}
+
+	payload.Color = color
 	payload.X = int(xOpt.IntValue())
 	payload.Y = int(yOpt.IntValue())
 
@@ -92,7 +112,20 @@ func drawCmd(ctx context.Context, interaction discordgo.Interaction, data discor
 	return &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: ":thumbsup:",
+			Content: fmt.Sprintf("Placing a pixel at (%d, %d)...", payload.X, payload.Y),
+			Flags:   discordgo.MessageFlagsEphemeral,
 		},
 	}, nil
 }
+
+// unknownColorResponse tells the user value is not a color they can draw
+// with.
+func unknownColorResponse(value string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Unknown color %q. Use a palette index or name, #RGB, #RRGGBB, rgb(r, g, b) or a CSS color name.", value),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
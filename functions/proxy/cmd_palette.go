@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("palette", paletteCmd)
+}
+
+type PaletteData struct {
+	Preset string   `json:"preset,omitempty"`
+	Colors []string `json:"colors,omitempty"`
+}
+
+func paletteCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.palette")
+	defer span.End()
+
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create Pub/Sub client", "error", err)
+		return nil, err
+	}
+
+	publisher := client.Publisher("session-events")
+	defer publisher.Stop()
+
+	palette := &PaletteData{}
+	if opt := data.GetOption("preset"); opt != nil {
+		palette.Preset = opt.StringValue()
+	}
+	if opt := data.GetOption("colors"); opt != nil {
+		palette.Colors = strings.FieldsFunc(opt.StringValue(), func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+
+	payload := ConfigureData{
+		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
+		Palette:  palette,
+	}
+
+	slog.DebugContext(ctx, "Palette payload", "payload", payload)
+	span.SetAttributes(
+		attribute.String("palette.canvas_id", payload.CanvasID),
+		attribute.String("palette.author_id", payload.AuthorID),
+	)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal palette payload", "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Palette payload", "body", string(body))
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["action"] = "configure"
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish palette message", "error", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Published palette message", "canvas_id", payload.CanvasID)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Palette update received! Applying...",
+		},
+	}, nil
+}
@@ -0,0 +1,149 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("protect", protectCmd)
+	RegisterCommand("unprotect", unprotectCmd)
+}
+
+// RegionData is a protected region, a zero ExpiresAt never expires.
+type RegionData struct {
+	Name      string    `json:"name"`
+	X0        int       `json:"x0"`
+	Y0        int       `json:"y0"`
+	X1        int       `json:"x1"`
+	Y1        int       `json:"y1"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func protectCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.protect")
+	defer span.End()
+
+	nameOpt := data.GetOption("name")
+	x0Opt := data.GetOption("x0")
+	y0Opt := data.GetOption("y0")
+	x1Opt := data.GetOption("x1")
+	y1Opt := data.GetOption("y1")
+	if nameOpt == nil || x0Opt == nil || y0Opt == nil || x1Opt == nil || y1Opt == nil {
+		slog.WarnContext(ctx, "Missing required options", "name", nameOpt, "x0", x0Opt, "y0", y0Opt, "x1", x1Opt, "y1", y1Opt)
+		return nil, fmt.Errorf("missing required options")
+	}
+
+	region := &RegionData{
+		Name: nameOpt.StringValue(),
+		X0:   int(x0Opt.IntValue()),
+		Y0:   int(y0Opt.IntValue()),
+		X1:   int(x1Opt.IntValue()),
+		Y1:   int(y1Opt.IntValue()),
+	}
+	if opt := data.GetOption("expires_in"); opt != nil {
+		region.ExpiresAt = time.Now().Add(time.Duration(opt.IntValue()) * time.Hour)
+	}
+
+	payload := ConfigureData{
+		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
+		Protect:  region,
+	}
+
+	span.SetAttributes(
+		attribute.String("protect.canvas_id", payload.CanvasID),
+		attribute.String("protect.name", region.Name),
+	)
+
+	if err := publishRegions(ctx, interaction, payload); err != nil {
+		return nil, err
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Protecting **%s**...", region.Name),
+		},
+	}, nil
+}
+
+func unprotectCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.unprotect")
+	defer span.End()
+
+	nameOpt := data.GetOption("name")
+	if nameOpt == nil {
+		slog.WarnContext(ctx, "Missing required options", "name", nameOpt)
+		return nil, fmt.Errorf("missing required options")
+	}
+
+	payload := ConfigureData{
+		CanvasID:  interaction.GuildID + interaction.ChannelID,
+		AuthorID:  interaction.Member.User.ID,
+		Unprotect: nameOpt.StringValue(),
+	}
+
+	span.SetAttributes(
+		attribute.String("unprotect.canvas_id", payload.CanvasID),
+		attribute.String("unprotect.name", payload.Unprotect),
+	)
+
+	if err := publishRegions(ctx, interaction, payload); err != nil {
+		return nil, err
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Unprotecting **%s**...", payload.Unprotect),
+		},
+	}, nil
+}
+
+func publishRegions(ctx context.Context, interaction discordgo.Interaction, payload ConfigureData) error {
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create Pub/Sub client", "error", err)
+		return err
+	}
+
+	publisher := client.Publisher("session-events")
+	defer publisher.Stop()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal region payload", "error", err)
+		return err
+	}
+	slog.DebugContext(ctx, "Region payload", "body", string(body))
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["action"] = "configure"
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish region message", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Published region message", "canvas_id", payload.CanvasID)
+	return nil
+}
@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("restore", restoreCmd)
+}
+
+type RestoreData struct {
+	CanvasID string    `json:"canvasId"`
+	AuthorID string    `json:"authorId"`
+	X0       int       `json:"x0"`
+	Y0       int       `json:"y0"`
+	X1       int       `json:"x1"`
+	Y1       int       `json:"y1"`
+	At       time.Time `json:"at"`
+	Snapshot bool      `json:"snapshot,omitempty"`
+}
+
+// parseRestoreTime reads a Unix timestamp or an RFC 3339 date.
+func parseRestoreTime(s string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func restoreCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.restore")
+	defer span.End()
+
+	x0Opt := data.GetOption("x0")
+	y0Opt := data.GetOption("y0")
+	x1Opt := data.GetOption("x1")
+	y1Opt := data.GetOption("y1")
+	if x0Opt == nil || y0Opt == nil || x1Opt == nil || y1Opt == nil {
+		slog.WarnContext(ctx, "Missing required options", "x0", x0Opt, "y0", y0Opt, "x1", x1Opt, "y1", y1Opt)
+		return nil, fmt.Errorf("missing required options")
+	}
+
+	payload := RestoreData{
+		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
+		X0:       int(x0Opt.IntValue()),
+		Y0:       int(y0Opt.IntValue()),
+		X1:       int(x1Opt.IntValue()),
+		Y1:       int(y1Opt.IntValue()),
+	}
+
+	reject := func(content string) (*discordgo.InteractionResponse, error) {
+		return &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: content,
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		}, nil
+	}
+
+	switch {
+	case data.GetOption("snapshot") != nil && data.GetOption("snapshot").BoolValue():
+		payload.Snapshot = true
+	case data.GetOption("at") != nil:
+		at, err := parseRestoreTime(data.GetOption("at").StringValue())
+		if err != nil {
+			slog.InfoContext(ctx, "Invalid restore time", "at", data.GetOption("at").StringValue(), "error", err)
+			return reject("Invalid time, use a Unix timestamp or a date like 2025-11-28T20:00:00Z.")
+		}
+		payload.At = at
+	case data.GetOption("minutes_ago") != nil:
+		payload.At = time.Now().Add(-time.Duration(data.GetOption("minutes_ago").IntValue()) * time.Minute)
+	default:
+		return reject("Tell me what to restore from: at, minutes_ago or snapshot.")
+	}
+
+	span.SetAttributes(
+		attribute.String("restore.canvas_id", payload.CanvasID),
+		attribute.Bool("restore.snapshot", payload.Snapshot),
+	)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal restore payload", "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Restore payload", "body", string(body))
+
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get Pub/Sub client", "error", err)
+		return nil, err
+	}
+
+	publisher := client.Publisher("drawing-restore")
+	defer publisher.Stop()
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish restore message", "error", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Published restore message", "canvas_id", payload.CanvasID)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Restoring the region...",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
@@ -0,0 +1,81 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("resume", resumeCmd)
+	RegisterCommand("restart", resumeCmd)
+}
+
+type ResumeData struct {
+	CanvasID string `json:"canvasId"`
+	AuthorID string `json:"authorId"`
+}
+
+func resumeCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.resume")
+	defer span.End()
+
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create Pub/Sub client", "error", err)
+		return nil, err
+	}
+
+	publisher := client.Publisher("session-events")
+	defer publisher.Stop()
+
+	payload := ResumeData{
+		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
+	}
+
+	slog.DebugContext(ctx, "Resume payload", "payload", payload)
+	span.SetAttributes(
+		attribute.String("resume.canvas_id", payload.CanvasID),
+		attribute.String("resume.author_id", payload.AuthorID),
+	)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal resume payload", "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Resume payload", "body", string(body))
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["action"] = "resume"
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish resume message", "error", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Published resume message", "canvas_id", payload.CanvasID)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Canvas resume command received! Resuming...",
+		},
+	}, nil
+}
@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("rollback", rollbackCmd)
+}
+
+// defaultRollbackWindow is how far back /rollback looks without hours.
+const defaultRollbackWindow = 24 * time.Hour
+
+// RollbackData bans TargetID and reverts the placements they made in
+// [From, To).
+type RollbackData struct {
+	CanvasID  string    `json:"canvasId"`
+	AuthorID  string    `json:"authorId"`
+	Moderator bool      `json:"moderator,omitempty"`
+	TargetID  string    `json:"targetId"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// rollbackCmd sends dry runs, the default, to snap for a preview image and
+// applies the rollback through drawing-rollback otherwise.
+func rollbackCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.rollback")
+	defer span.End()
+
+	if !isModerator(interaction.Member) {
+		return &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Only moderators can roll back pixels.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		}, nil
+	}
+
+	userOpt := data.GetOption("user")
+	if userOpt == nil {
+		slog.WarnContext(ctx, "Missing required options", "user", userOpt)
+		return nil, fmt.Errorf("missing required options")
+	}
+
+	window := defaultRollbackWindow
+	if opt := data.GetOption("hours"); opt != nil {
+		window = time.Duration(opt.IntValue()) * time.Hour
+	}
+	dryRun := true
+	if opt := data.GetOption("dry_run"); opt != nil {
+		dryRun = opt.BoolValue()
+	}
+
+	now := time.Now()
+	payload := RollbackData{
+		CanvasID:  interaction.GuildID + interaction.ChannelID,
+		AuthorID:  interaction.Member.User.ID,
+		Moderator: true,
+		TargetID:  userOpt.UserValue(nil).ID,
+		From:      now.Add(-window),
+		To:        now,
+	}
+
+	span.SetAttributes(
+		attribute.String("rollback.canvas_id", payload.CanvasID),
+		attribute.String("rollback.target_id", payload.TargetID),
+		attribute.Bool("rollback.dry_run", dryRun),
+	)
+
+	topic := "drawing-rollback"
+	var body []byte
+	var err error
+	if dryRun {
+		topic = "command.snap"
+		body, err = json.Marshal(SnapData{
+			CanvasID: payload.CanvasID,
+			AuthorID: payload.AuthorID,
+			Mode:     "rollback",
+			Rollback: &SnapRollbackData{
+				TargetID: payload.TargetID,
+				From:     payload.From,
+				To:       payload.To,
+			},
+		})
+	} else {
+		body, err = json.Marshal(payload)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal rollback payload", "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Rollback payload", "topic", topic, "body", string(body))
+
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get Pub/Sub client", "error", err)
+		return nil, err
+	}
+
+	publisher := client.Publisher(topic)
+	defer publisher.Stop()
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish rollback message", "error", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Published rollback message", "canvas_id", payload.CanvasID, "target_id", payload.TargetID, "dry_run", dryRun)
+
+	content := fmt.Sprintf("Rolling back <@%s>...", payload.TargetID)
+	if dryRun {
+		content = fmt.Sprintf("Previewing the rollback of <@%s>...", payload.TargetID)
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
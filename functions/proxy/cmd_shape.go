@@ -0,0 +1,123 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("rect", shapeCmd(canvas.ShapeRect))
+	RegisterCommand("line", shapeCmd(canvas.ShapeLine))
+	RegisterCommand("fill", shapeCmd(canvas.ShapeFill))
+}
+
+type ShapeData struct {
+	Shape    canvas.Shape `json:"shape"`
+	X0       int          `json:"x0"`
+	Y0       int          `json:"y0"`
+	X1       int          `json:"x1,omitempty"`
+	Y1       int          `json:"y1,omitempty"`
+	Color    string       `json:"color"`
+	AuthorID string       `json:"authorId"`
+	CanvasID string       `json:"canvasId"`
+}
+
+// shapeCmd publishes an admin drawing command on drawing-shape. Fills only
+// take x0 and y0.
+func shapeCmd(shape canvas.Shape) CommandHandler {
+	return func(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+		ctx, span := tracer.Start(ctx, "command."+string(shape))
+		defer span.End()
+
+		client, err := PubSub()
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get Pub/Sub client", "error", err)
+			return nil, err
+		}
+
+		publisher := client.Publisher("drawing-shape")
+		defer publisher.Stop()
+
+		payload := ShapeData{
+			Shape:    shape,
+			CanvasID: interaction.GuildID + interaction.ChannelID,
+			AuthorID: interaction.Member.User.ID,
+		}
+
+		colorOpt := data.GetOption("color")
+		x0Opt := data.GetOption("x0")
+		y0Opt := data.GetOption("y0")
+		if colorOpt == nil || x0Opt == nil || y0Opt == nil {
+			slog.WarnContext(ctx, "Missing required options", "color", colorOpt, "x0", x0Opt, "y0", y0Opt)
+			return nil, fmt.Errorf("missing required options")
+		}
+		payload.X0 = int(x0Opt.IntValue())
+		payload.Y0 = int(y0Opt.IntValue())
+
+		if shape != canvas.ShapeFill {
+			x1Opt := data.GetOption("x1")
+			y1Opt := data.GetOption("y1")
+			if x1Opt == nil || y1Opt == nil {
+				slog.WarnContext(ctx, "Missing required options", "x1", x1Opt, "y1", y1Opt)
+				return nil, fmt.Errorf("missing required options")
+			}
+			payload.X1 = int(x1Opt.IntValue())
+			payload.Y1 = int(y1Opt.IntValue())
+		}
+
+		color, err := canvas.NormalizeColor(colorOpt.StringValue())
+		if err != nil {
+			slog.InfoContext(ctx, "Invalid color", "color", colorOpt.StringValue(), "error", err)
+			return unknownColorResponse(colorOpt.StringValue()), nil
+		}
+		payload.Color = color
+
+		span.SetAttributes(
+			attribute.String("shape.kind", string(payload.Shape)),
+			attribute.String("shape.canvas_id", payload.CanvasID),
+			attribute.String("shape.author_id", payload.AuthorID),
+		)
+
+		body, err := json.Marshal(payload)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to marshal shape payload", "error", err)
+			return nil, err
+		}
+		slog.DebugContext(ctx, "Shape payload", "body", string(body))
+
+		msg := &pubsub.Message{
+			Data:       body,
+			Attributes: make(map[string]string),
+		}
+
+		msg.Attributes["discord_interaction_token"] = interaction.Token
+
+		otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+		result := publisher.Publish(ctx, msg)
+
+		_, err = result.Get(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to publish shape message", "error", err)
+			return nil, err
+		}
+
+		slog.InfoContext(ctx, "Published shape message", "canvas_id", payload.CanvasID, "shape", payload.Shape, "color", payload.Color)
+
+		return &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Drawing...",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		}, nil
+	}
+}
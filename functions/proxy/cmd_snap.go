@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"time"
 
 	"cloud.google.com/go/pubsub/v2"
 	"github.com/bwmarrin/discordgo"
@@ -19,6 +20,52 @@ func init() {
 type SnapData struct {
 	CanvasID string `json:"canvas_id"`
 	AuthorID string `json:"author_id"`
+	Mode     string `json:"mode,omitempty"`
+	Regions  bool   `json:"regions,omitempty"`
+
+	View        *SnapViewData        `json:"view,omitempty"`
+	Timelapse   *SnapTimelapseData   `json:"timelapse,omitempty"`
+	Heatmap     *SnapHeatmapData     `json:"heatmap,omitempty"`
+	Attribution *SnapAttributionData `json:"attribution,omitempty"`
+	Rollback    *SnapRollbackData    `json:"rollback,omitempty"`
+}
+
+// SnapViewData zooms the snapshot on a region, zero sizes extend it to the
+// canvas edge and a zero zoom fits it in 1024 pixels.
+type SnapViewData struct {
+	X      int  `json:"x"`
+	Y      int  `json:"y"`
+	Width  int  `json:"width"`
+	Height int  `json:"height"`
+	Zoom   int  `json:"zoom"`
+	Grid   bool `json:"grid"`
+	Rulers bool `json:"rulers"`
+}
+
+// SnapTimelapseData shapes a timelapse, zero values keep the defaults of
+// snap.
+type SnapTimelapseData struct {
+	FrameInterval int `json:"frame_interval,omitempty"`
+	MaxFrames     int `json:"max_frames,omitempty"`
+	Scale         int `json:"scale,omitempty"`
+}
+
+// SnapHeatmapData picks what a heatmap shows, "changes" or "recency".
+type SnapHeatmapData struct {
+	By string `json:"by"`
+}
+
+// SnapAttributionData highlights the pixels of one user in an attribution
+// snapshot.
+type SnapAttributionData struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+// SnapRollbackData asks snap for a rollback dry run, see RollbackData.
+type SnapRollbackData struct {
+	TargetID string    `json:"target_id"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
 }
 
 func snapCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
@@ -39,10 +86,82 @@ func snapCmd(ctx context.Context, interaction discordgo.Interaction, data discor
 		AuthorID: interaction.Member.User.ID,
 	}
 
+	if opt := data.GetOption("mode"); opt != nil {
+		payload.Mode = opt.StringValue()
+	}
+	// Picking a user implies the attribution mode.
+	if opt := data.GetOption("user"); opt != nil {
+		payload.Mode = "attribution"
+		payload.Attribution = &SnapAttributionData{UserID: opt.UserValue(nil).ID}
+	}
+	if payload.Mode == "heatmap" {
+		if !isModerator(interaction.Member) {
+			return &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Content: "Only moderators can snap a heatmap.",
+					Flags:   discordgo.MessageFlagsEphemeral,
+				},
+			}, nil
+		}
+		payload.Heatmap = &SnapHeatmapData{}
+		if opt := data.GetOption("heat"); opt != nil {
+			payload.Heatmap.By = opt.StringValue()
+		}
+	}
+	if opt := data.GetOption("regions"); opt != nil {
+		payload.Regions = opt.BoolValue()
+	}
+
+	var view SnapViewData
+	if opt := data.GetOption("x"); opt != nil {
+		view.X = int(opt.IntValue())
+	}
+	if opt := data.GetOption("y"); opt != nil {
+		view.Y = int(opt.IntValue())
+	}
+	if opt := data.GetOption("width"); opt != nil {
+		view.Width = int(opt.IntValue())
+	}
+	if opt := data.GetOption("height"); opt != nil {
+		view.Height = int(opt.IntValue())
+	}
+	if opt := data.GetOption("zoom"); opt != nil {
+		view.Zoom = int(opt.IntValue())
+	}
+	if opt := data.GetOption("grid"); opt != nil {
+		view.Grid = opt.BoolValue()
+	}
+	if opt := data.GetOption("rulers"); opt != nil {
+		view.Rulers = opt.BoolValue()
+	}
+	if view != (SnapViewData{}) {
+		payload.View = &view
+	}
+
+	var timelapse SnapTimelapseData
+	if opt := data.GetOption("frame_interval"); opt != nil {
+		timelapse.FrameInterval = int(opt.IntValue())
+	}
+	if opt := data.GetOption("max_frames"); opt != nil {
+		timelapse.MaxFrames = int(opt.IntValue())
+	}
+	if opt := data.GetOption("scale"); opt != nil {
+		timelapse.Scale = int(opt.IntValue())
+	}
+	if timelapse != (SnapTimelapseData{}) {
+		// Timelapse options imply the timelapse mode.
+		if payload.Mode == "" {
+			payload.Mode = "timelapse"
+		}
+		payload.Timelapse = &timelapse
+	}
+
 	slog.DebugContext(ctx, "Snap payload", "payload", payload)
 	span.SetAttributes(
 		attribute.String("snap.canvas_id", payload.CanvasID),
 		attribute.String("snap.author_id", payload.AuthorID),
+		attribute.String("snap.mode", payload.Mode),
 	)
 
 	body, err := json.Marshal(payload)
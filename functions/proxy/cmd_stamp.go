@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/bwmarrin/discordgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func init() {
+	RegisterCommand("stamp", stampCmd)
+}
+
+type StampData struct {
+	ImageURL string `json:"imageUrl"`
+	X        int    `json:"x"`
+	Y        int    `json:"y"`
+	Width    int    `json:"width,omitempty"`
+	Dither   bool   `json:"dither,omitempty"`
+	AuthorID string `json:"authorId"`
+	CanvasID string `json:"canvasId"`
+}
+
+var stampContentTypes = []string{"image/png", "image/jpeg", "image/gif"}
+
+func stampCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
+	ctx, span := tracer.Start(ctx, "command.stamp")
+	defer span.End()
+
+	client, err := PubSub()
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get Pub/Sub client", "error", err)
+		return nil, err
+	}
+
+	publisher := client.Publisher("drawing-stamp")
+	defer publisher.Stop()
+
+	payload := StampData{
+		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
+	}
+
+	imageOpt := data.GetOption("image")
+	xOpt := data.GetOption("x")
+	yOpt := data.GetOption("y")
+	if imageOpt == nil || xOpt == nil || yOpt == nil {
+		slog.WarnContext(ctx, "Missing required options", "image", imageOpt, "x", xOpt, "y", yOpt)
+		return nil, fmt.Errorf("missing required options")
+	}
+
+	var attachment *discordgo.MessageAttachment
+	if data.Resolved != nil {
+		attachment = data.Resolved.Attachments[imageOpt.StringValue()]
+	}
+	if attachment == nil {
+		slog.WarnContext(ctx, "Attachment not resolved", "id", imageOpt.StringValue())
+		return nil, fmt.Errorf("attachment %s not resolved", imageOpt.StringValue())
+	}
+
+	contentType, _, _ := strings.Cut(attachment.ContentType, ";")
+	if !slices.Contains(stampContentTypes, contentType) {
+		slog.InfoContext(ctx, "Unsupported attachment", "content_type", attachment.ContentType)
+		return &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Only PNG, JPEG and GIF images can be stamped.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		}, nil
+	}
+
+	payload.ImageURL = attachment.URL
+	payload.X = int(xOpt.IntValue())
+	payload.Y = int(yOpt.IntValue())
+	if opt := data.GetOption("width"); opt != nil {
+		payload.Width = int(opt.IntValue())
+	}
+	if opt := data.GetOption("dither"); opt != nil {
+		payload.Dither = opt.BoolValue()
+	}
+
+	span.SetAttributes(
+		attribute.String("stamp.canvas_id", payload.CanvasID),
+		attribute.String("stamp.author_id", payload.AuthorID),
+		attribute.Int("stamp.size_bytes", attachment.Size),
+	)
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal stamp payload", "error", err)
+		return nil, err
+	}
+	slog.DebugContext(ctx, "Stamp payload", "body", string(body))
+
+	msg := &pubsub.Message{
+		Data:       body,
+		Attributes: make(map[string]string),
+	}
+
+	msg.Attributes["discord_interaction_token"] = interaction.Token
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	result := publisher.Publish(ctx, msg)
+
+	_, err = result.Get(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to publish stamp message", "error", err)
+		return nil, err
+	}
+
+	slog.InfoContext(ctx, "Published stamp message", "canvas_id", payload.CanvasID, "x", payload.X, "y", payload.Y)
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: "Stamping the image...",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}, nil
+}
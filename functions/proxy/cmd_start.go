@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/pubsub/v2"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
 	"github.com/bwmarrin/discordgo"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -15,18 +16,20 @@ import (
 
 func init() {
 	RegisterCommand("start", startCmd)
-	RegisterCommand("restart", startCmd)
-
 }
 
 type StartData struct {
 	CanvasID  string    `json:"canvasId"`
 	AdminID   string    `json:"adminId"`
+	ChannelID string    `json:"channelId"`
 	Name      string    `json:"name"`
 	Width     int       `json:"width"`
 	Height    int       `json:"height"`
 	StartDate time.Time `json:"startDate"`
 	EndDate   time.Time `json:"endDate"`
+
+	Cooldown *canvas.CooldownPolicy `json:"cooldown,omitempty"`
+	Palette  *PaletteData           `json:"palette,omitempty"`
 }
 
 func startCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
@@ -42,21 +45,50 @@ func startCmd(ctx context.Context, interaction discordgo.Interaction, data disco
 	publisher := client.Publisher("session-events")
 	defer publisher.Stop()
 
+	startDate := time.Now()
+	if opt := data.GetOption("starts_in"); opt != nil {
+		startDate = startDate.Add(time.Duration(opt.IntValue()) * time.Minute)
+	}
+	duration := 24 * time.Hour
+	if opt := data.GetOption("duration"); opt != nil {
+		duration = time.Duration(opt.IntValue()) * time.Hour
+	}
+
 	payload := StartData{
 		CanvasID:  interaction.GuildID + interaction.ChannelID,
 		AdminID:   interaction.Member.User.ID,
+		ChannelID: interaction.ChannelID,
 		Name:      interaction.Member.User.Username + "'s Canvas",
-		StartDate: time.Now(),
-		EndDate:   time.Now().Add(24 * time.Hour),
+		StartDate: startDate,
+		EndDate:   startDate.Add(duration),
 	}
 
 	payload.Width = int(data.GetOption("width").IntValue())
 	payload.Height = int(data.GetOption("height").IntValue())
 
+	intervalOpt := data.GetOption("cooldown")
+	burstOpt := data.GetOption("burst")
+	if intervalOpt != nil || burstOpt != nil {
+		cooldown := canvas.DefaultCooldownPolicy
+		if intervalOpt != nil {
+			cooldown.Interval = int(intervalOpt.IntValue())
+		}
+		if burstOpt != nil {
+			cooldown.Burst = int(burstOpt.IntValue())
+		}
+		payload.Cooldown = &cooldown
+	}
+
+	if opt := data.GetOption("palette"); opt != nil {
+		payload.Palette = &PaletteData{Preset: opt.StringValue()}
+	}
+
 	slog.DebugContext(ctx, "Start payload", "payload", payload)
 	span.SetAttributes(
 		attribute.String("start.canvas_id", payload.CanvasID),
 		attribute.String("start.author_id", payload.AdminID),
+		attribute.String("start.start_date", payload.StartDate.Format(time.RFC3339)),
+		attribute.String("start.end_date", payload.EndDate.Format(time.RFC3339)),
 	)
 
 	body, err := json.Marshal(payload)
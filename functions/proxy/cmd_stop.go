@@ -18,6 +18,7 @@ func init() {
 
 type StopData struct {
 	CanvasID string `json:"canvasId"`
+	AuthorID string `json:"authorId"`
 }
 
 func stopCmd(ctx context.Context, interaction discordgo.Interaction, data discordgo.ApplicationCommandInteractionData) (*discordgo.InteractionResponse, error) {
@@ -33,13 +34,15 @@ func stopCmd(ctx context.Context, interaction discordgo.Interaction, data discor
 	publisher := client.Publisher("session-events")
 	defer publisher.Stop()
 
-	payload := PauseData{
+	payload := StopData{
 		CanvasID: interaction.GuildID + interaction.ChannelID,
+		AuthorID: interaction.Member.User.ID,
 	}
 
 	slog.DebugContext(ctx, "Stop payload", "payload", payload)
 	span.SetAttributes(
 		attribute.String("stop.canvas_id", payload.CanvasID),
+		attribute.String("stop.author_id", payload.AuthorID),
 	)
 
 	body, err := json.Marshal(payload)
@@ -3,16 +3,19 @@ module github.com/Evan-Lab/cloud-native/functions/proxy
 go 1.24.9
 
 require (
-	github.com/Evan-Lab/cloud-native/lib/go v0.0.0-20251114144537-4a898f1e4fd0
+	github.com/Evan-Lab/cloud-native/lib/go v0.0.0-20251128202231-e34e95f3119d
 	github.com/GoogleCloudPlatform/functions-framework-go v1.9.2
 	github.com/bwmarrin/discordgo v0.29.0
 )
 
 require (
 	cloud.google.com/go v0.121.6 // indirect
+	cloud.google.com/go/firestore v1.20.0 // indirect
+	cloud.google.com/go/longrunning v0.6.7 // indirect
 	cloud.google.com/go/trace v1.11.6 // indirect
 	github.com/GoogleCloudPlatform/opentelemetry-operations-go/internal/resourcemapping v0.54.0 // indirect
 	go.opencensus.io v0.24.0 // indirect
+	golang.org/x/image v0.33.0 // indirect
 	golang.org/x/time v0.13.0 // indirect
 )
 
@@ -52,9 +55,9 @@ require (
 	golang.org/x/crypto v0.42.0 // indirect
 	golang.org/x/net v0.44.0 // indirect
 	golang.org/x/oauth2 v0.31.0 // indirect
-	golang.org/x/sync v0.17.0 // indirect
+	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.36.0 // indirect
-	golang.org/x/text v0.29.0 // indirect
+	golang.org/x/text v0.31.0 // indirect
 	google.golang.org/api v0.249.0
 	google.golang.org/genproto v0.0.0-20250922171735-9219d122eba9 // indirect
 	google.golang.org/genproto/googleapis/api v0.0.0-20250922171735-9219d122eba9 // indirect
@@ -62,3 +65,5 @@ require (
 	google.golang.org/grpc v1.75.1 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+replace github.com/Evan-Lab/cloud-native/lib/go => ../../lib/go
@@ -1,58 +0,0 @@
-package reset_session
-
-import (
-	"context"
-	"fmt"
-	"log/slog"
-
-	"go.opentelemetry.io/otel/trace"
-)
-
-func handlerWithSpanContext(handler slog.Handler) *spanContextLogHandler {
-	return &spanContextLogHandler{Handler: handler}
-}
-
-// spanContextLogHandler is a slog.Handler which adds attributes from the
-// span context.
-type spanContextLogHandler struct {
-	slog.Handler
-}
-
-// Handle overrides slog.Handler's Handle method. This adds attributes from the
-// span context to the slog.Record.
-func (t *spanContextLogHandler) Handle(ctx context.Context, record slog.Record) error {
-	// Get the SpanContext from the context.
-	if s := trace.SpanContextFromContext(ctx); s.IsValid() {
-		// Add trace context attributes following Cloud Logging structured log format described
-		// in https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
-		traceName := fmt.Sprintf("projects/%s/traces/%s", projectID, s.TraceID().String())
-		record.AddAttrs(
-			slog.String("logging.googleapis.com/trace", traceName),
-		)
-		record.AddAttrs(
-			slog.String("logging.googleapis.com/spanId", s.SpanID().String()),
-		)
-		record.AddAttrs(
-			slog.Bool("logging.googleapis.com/trace_sampled", s.TraceFlags().IsSampled()),
-		)
-	}
-	return t.Handler.Handle(ctx, record)
-}
-
-func replacer(groups []string, a slog.Attr) slog.Attr {
-	// Rename attribute keys to match Cloud Logging structured log format
-	switch a.Key {
-	case slog.LevelKey:
-		a.Key = "severity"
-		// Map slog.Level string values to Cloud Logging LogSeverity
-		// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#LogSeverity
-		if level := a.Value.Any().(slog.Level); level == slog.LevelWarn {
-			a.Value = slog.StringValue("WARNING")
-		}
-	case slog.TimeKey:
-		a.Key = "timestamp"
-	case slog.MessageKey:
-		a.Key = "message"
-	}
-	return a
-}
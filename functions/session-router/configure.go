@@ -0,0 +1,219 @@
+package session_router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+)
+
+type ConfigureInput struct {
+	CanvasID string         `json:"canvasId"`
+	AuthorID string         `json:"authorId"`
+	Cooldown *CooldownInput `json:"cooldown,omitempty"`
+	Palette  *PaletteInput  `json:"palette,omitempty"`
+	// Protect adds or replaces a region, Unprotect removes one by name.
+	Protect   *RegionInput `json:"protect,omitempty"`
+	Unprotect string       `json:"unprotect,omitempty"`
+}
+
+// CooldownInput holds the cooldown settings to change, nil fields are left
+// as they are. With a RoleID, Interval sets the override of that role and a
+// nil Interval removes it.
+type CooldownInput struct {
+	Interval *int   `json:"interval,omitempty"`
+	Burst    *int   `json:"burst,omitempty"`
+	RoleID   string `json:"roleId,omitempty"`
+}
+
+// PaletteInput picks one of the canvas.Palettes presets or a custom list
+// of #RRGGBB colors.
+type PaletteInput struct {
+	Preset string   `json:"preset,omitempty"`
+	Colors []string `json:"colors,omitempty"`
+}
+
+// RegionInput is a protected region, see canvas.Region. A zero ExpiresAt
+// keeps it locked until removed.
+type RegionInput struct {
+	Name      string    `json:"name"`
+	X0        int       `json:"x0"`
+	Y0        int       `json:"y0"`
+	X1        int       `json:"x1"`
+	Y1        int       `json:"y1"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (p PaletteInput) resolve() ([]string, error) {
+	if p.Preset != "" {
+		palette, ok := canvas.Palettes[p.Preset]
+		if !ok {
+			return nil, &RejectedError{Reason: fmt.Sprintf("unknown palette %q", p.Preset)}
+		}
+		return palette, nil
+	}
+
+	palette, err := canvas.NewPalette(p.Colors)
+	if err != nil {
+		return nil, &RejectedError{Reason: err.Error()}
+	}
+	return palette, nil
+}
+
+func handleConfigure(ctx context.Context, store canvas.Store, event Event) error {
+	var input ConfigureInput
+	if err := json.Unmarshal(event.Data, &input); err != nil {
+		slog.Error("Invalid JSON", "raw", string(event.Data))
+		return events.Invalid(err)
+	}
+
+	if input.CanvasID == "" || input.AuthorID == "" {
+		slog.Error("Missing required fields", "input", input)
+		return events.Invalid(errors.New("missing required fields"))
+	}
+
+	c, err := Configure(ctx, store, input)
+	if err != nil {
+		return err
+	}
+
+	if err := FollowUp(ctx, event.InteractionToken, settingsMessage(c)); err != nil {
+		slog.Error("Failed to send canvas settings", "canvasId", c.ID, "error", err)
+	}
+	return nil
+}
+
+// Configure applies the settings changed by the canvas admin.
+func Configure(ctx context.Context, store canvas.Store, input ConfigureInput) (*canvas.Canvas, error) {
+	c, _, err := loadCanvas(ctx, store, input.CanvasID, canvas.ActionConfigure)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.AuthorID != c.AdminID {
+		return nil, &RejectedError{Reason: "only the canvas admin can change its settings"}
+	}
+
+	if input.Cooldown != nil {
+		policy, err := applyCooldown(c.Cooldown, *input.Cooldown)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := store.SetCooldownPolicy(ctx, c.ID, policy); err != nil {
+			slog.Error("Failed to update cooldown", "canvasId", c.ID, "error", err)
+			return nil, err
+		}
+		c.Cooldown = &policy
+		slog.Info("Cooldown updated", "canvasId", c.ID, "policy", policy)
+	}
+
+	// Pixels already placed keep their color even when it leaves the
+	// palette.
+	if input.Palette != nil {
+		palette, err := input.Palette.resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := store.SetPalette(ctx, c.ID, palette); err != nil {
+			slog.Error("Failed to update palette", "canvasId", c.ID, "error", err)
+			return nil, err
+		}
+		c.Palette = palette
+		slog.Info("Palette updated", "canvasId", c.ID, "colors", len(palette))
+	}
+
+	// Expired regions are dropped whenever the list is saved.
+	if input.Protect != nil || input.Unprotect != "" {
+		now := time.Now()
+
+		var regions []canvas.Region
+		if r := input.Protect; r != nil {
+			region, err := c.NewRegion(r.Name, r.X0, r.Y0, r.X1, r.Y1, r.ExpiresAt)
+			if err != nil {
+				return nil, &RejectedError{Reason: err.Error()}
+			}
+			regions = c.WithRegion(region, now)
+		} else {
+			var found bool
+			regions, found = c.WithoutRegion(input.Unprotect, now)
+			if !found {
+				return nil, &RejectedError{Reason: fmt.Sprintf("there is no protected region named %q", input.Unprotect)}
+			}
+		}
+
+		if err := store.SetRegions(ctx, c.ID, regions); err != nil {
+			slog.Error("Failed to update regions", "canvasId", c.ID, "error", err)
+			return nil, err
+		}
+		c.Regions = regions
+		slog.Info("Regions updated", "canvasId", c.ID, "regions", len(regions))
+	}
+
+	return c, nil
+}
+
+func applyCooldown(current *canvas.CooldownPolicy, input CooldownInput) (canvas.CooldownPolicy, error) {
+	policy := canvas.DefaultCooldownPolicy
+	if current != nil {
+		policy = *current
+	}
+	policy.RoleIntervals = maps.Clone(policy.RoleIntervals)
+
+	if input.Interval != nil && *input.Interval < 0 {
+		return policy, &RejectedError{Reason: "the interval cannot be negative"}
+	}
+	if input.Burst != nil && *input.Burst < 1 {
+		return policy, &RejectedError{Reason: "the burst must be at least 1"}
+	}
+
+	if input.Burst != nil {
+		policy.Burst = *input.Burst
+	}
+
+	switch {
+	case input.RoleID == "":
+		if input.Interval != nil {
+			policy.Interval = *input.Interval
+		}
+	case input.Interval != nil:
+		if policy.RoleIntervals == nil {
+			policy.RoleIntervals = make(map[string]int)
+		}
+		policy.RoleIntervals[input.RoleID] = *input.Interval
+	default:
+		delete(policy.RoleIntervals, input.RoleID)
+	}
+
+	return policy, nil
+}
+
+func settingsMessage(c *canvas.Canvas) string {
+	policy := canvas.DefaultCooldownPolicy
+	if c.Cooldown != nil {
+		policy = *c.Cooldown
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Cooldown of **%s**: %ds, burst of %d pixels", c.Name, policy.Interval, policy.Burst)
+	for _, role := range slices.Sorted(maps.Keys(policy.RoleIntervals)) {
+		fmt.Fprintf(&b, "\n<@&%s>: %ds", role, policy.RoleIntervals[role])
+	}
+	fmt.Fprintf(&b, "\nPalette: %s", strings.Join(c.PaletteColors(), " "))
+	for _, r := range c.ActiveRegions(time.Now()) {
+		fmt.Fprintf(&b, "\nProtected **%s**: (%d, %d) to (%d, %d)", r.Name, r.X0, r.Y0, r.X1, r.Y1)
+		if !r.ExpiresAt.IsZero() {
+			fmt.Fprintf(&b, " until <t:%d:f>", r.ExpiresAt.Unix())
+		}
+	}
+	return b.String()
+}
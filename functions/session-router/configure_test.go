@@ -0,0 +1,132 @@
+package session_router_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	session_router "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+)
+
+func configure(ctx context.Context, store canvas.Store, data string) error {
+	return session_router.Route(ctx, store, session_router.Event{Action: canvas.ActionConfigure, Data: []byte(data)})
+}
+
+func TestConfigureCooldown(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "cooldown": {"interval": 30, "burst": 3}}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	c, _ := store.GetCanvas(ctx, "c1")
+	if c.Cooldown == nil || c.Cooldown.Interval != 30 || c.Cooldown.Burst != 3 {
+		t.Fatalf("unexpected cooldown: %+v", c.Cooldown)
+	}
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "cooldown": {"interval": 5, "roleId": "vip"}}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	c, _ = store.GetCanvas(ctx, "c1")
+	if c.Cooldown.RoleIntervals["vip"] != 5 || c.Cooldown.Interval != 30 || c.Cooldown.Burst != 3 {
+		t.Fatalf("role override not added: %+v", c.Cooldown)
+	}
+
+	// Without an interval, the role override is removed.
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "cooldown": {"roleId": "vip"}}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	c, _ = store.GetCanvas(ctx, "c1")
+	if _, ok := c.Cooldown.RoleIntervals["vip"]; ok || c.Cooldown.Interval != 30 {
+		t.Fatalf("role override not removed: %+v", c.Cooldown)
+	}
+
+	for _, data := range []string{
+		`{"canvasId": "c1", "authorId": "admin", "cooldown": {"interval": -1}}`,
+		`{"canvasId": "c1", "authorId": "admin", "cooldown": {"burst": 0}}`,
+	} {
+		if err := configure(ctx, store, data); events.KindOf(err) != events.KindRejected {
+			t.Errorf("Route with %s: got %v, want rejected", data, err)
+		}
+	}
+}
+
+func TestConfigureRejectsNonAdmin(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "other", "cooldown": {"interval": 1}}`); events.KindOf(err) != events.KindRejected {
+		t.Fatalf("Route by non-admin: got %v, want rejected", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); c.Cooldown != nil {
+		t.Fatalf("cooldown changed by non-admin: %+v", c.Cooldown)
+	}
+
+	if err := configure(ctx, store, `{"canvasId": "c1"}`); events.KindOf(err) != events.KindInvalid {
+		t.Fatalf("Route without author: got %v, want invalid", err)
+	}
+}
+
+func TestConfigurePalette(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "palette": {"preset": "32"}}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); !slices.Equal(c.Palette, canvas.Palettes[canvas.Palette32]) {
+		t.Fatalf("got palette %v, want the 32 colors preset", c.Palette)
+	}
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "palette": {"colors": ["#FFFFFF", "#000000"]}}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); len(c.Palette) != 2 {
+		t.Fatalf("got palette %v, want 2 colors", c.Palette)
+	}
+
+	for _, data := range []string{
+		`{"canvasId": "c1", "authorId": "admin", "palette": {"preset": "8"}}`,
+		`{"canvasId": "c1", "authorId": "admin", "palette": {"colors": ["red"]}}`,
+	} {
+		if err := configure(ctx, store, data); events.KindOf(err) != events.KindRejected {
+			t.Errorf("Route with %s: got %v, want rejected", data, err)
+		}
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); len(c.Palette) != 2 {
+		t.Fatalf("rejected palette was saved: %v", c.Palette)
+	}
+}
+
+func TestConfigureRegions(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "protect": {"name": "logo", "x0": 2, "y0": 2, "x1": 0, "y1": 0}}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	c, _ := store.GetCanvas(ctx, "c1")
+	if len(c.Regions) != 1 || c.Regions[0].Name != "logo" {
+		t.Fatalf("unexpected regions: %+v", c.Regions)
+	}
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "protect": {"name": "out", "x0": 0, "y0": 0, "x1": 4, "y1": 4}}`); events.KindOf(err) != events.KindRejected {
+		t.Errorf("Route with region out of bounds: got %v, want rejected", err)
+	}
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "unprotect": "missing"}`); events.KindOf(err) != events.KindRejected {
+		t.Errorf("Route unprotecting a missing region: got %v, want rejected", err)
+	}
+
+	if err := configure(ctx, store, `{"canvasId": "c1", "authorId": "admin", "unprotect": "logo"}`); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); len(c.Regions) != 0 {
+		t.Fatalf("region not removed: %+v", c.Regions)
+	}
+}
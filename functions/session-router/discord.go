@@ -0,0 +1,67 @@
+package session_router
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/discord"
+	"github.com/bwmarrin/discordgo"
+)
+
+// FollowUp sends an ephemeral follow-up message to the interaction that
+// published the session event. Events without a token are not reported.
+func FollowUp(ctx context.Context, interactionToken string, content string) error {
+	if interactionToken == "" {
+		return nil
+	}
+
+	s, err := discord.Session()
+	if err != nil {
+		slog.ErrorContext(ctx, "discord.Session", "error", err)
+		return err
+	}
+	defer s.Close()
+
+	interaction := &discordgo.Interaction{
+		AppID: s.State.Application.ID,
+		Token: interactionToken,
+	}
+
+	st, err := s.FollowupMessageCreate(interaction, true, &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "FollowupMessageCreate", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Sent interaction follow-up", "message_id", st.ID, "channel_id", st.ChannelID)
+	return nil
+}
+
+// Announce posts content in the channel the canvas runs in. Canvases
+// created before the channel was recorded are skipped.
+func Announce(ctx context.Context, c *canvas.Canvas, content string) error {
+	if c.ChannelID == "" {
+		slog.WarnContext(ctx, "No channel to announce in", "canvasId", c.ID)
+		return nil
+	}
+
+	s, err := discord.Session()
+	if err != nil {
+		slog.ErrorContext(ctx, "discord.Session", "error", err)
+		return err
+	}
+	defer s.Close()
+
+	st, err := s.ChannelMessageSend(c.ChannelID, content)
+	if err != nil {
+		slog.ErrorContext(ctx, "ChannelMessageSend", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Sent announcement", "message_id", st.ID, "channel_id", st.ChannelID)
+	return nil
+}
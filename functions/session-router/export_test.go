@@ -0,0 +1,15 @@
+package session_router
+
+import (
+	"context"
+
+	"cloud.google.com/go/pubsub/v2"
+)
+
+// SetPublishSnap replaces the snap publisher until the returned function
+// restores it.
+func SetPublishSnap(publish func(ctx context.Context, msg *pubsub.Message) error) (restore func()) {
+	previous := publishSnap
+	publishSnap = publish
+	return func() { publishSnap = previous }
+}
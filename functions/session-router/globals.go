@@ -0,0 +1,32 @@
+package session_router
+
+import (
+	"os"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+var projectID string
+var databaseName string
+
+// dedupWindow is how long processed message IDs are remembered.
+var dedupWindow time.Duration
+
+func init() {
+	projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	if projectID == "" {
+		panic("GOOGLE_CLOUD_PROJECT not set in environment")
+	}
+
+	databaseName = os.Getenv("FIRESTORE_DB")
+	if databaseName == "" {
+		panic("FIRESTORE_DB not set in environment")
+	}
+
+	var err error
+	dedupWindow, err = canvas.ParseDedupWindow(os.Getenv("DEDUP_WINDOW"))
+	if err != nil {
+		panic(err)
+	}
+}
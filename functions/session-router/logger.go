@@ -1,4 +1,4 @@
-package start_session
+package session_router
 
 import (
 	"context"
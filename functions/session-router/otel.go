@@ -1,4 +1,4 @@
-package pause_session
+package session_router
 
 import (
 	"context"
@@ -0,0 +1,31 @@
+package session_router
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func handlePause(ctx context.Context, store canvas.Store, event Event) error {
+	input, err := decodeSessionInput(event.Data, event.Action)
+	if err != nil {
+		return err
+	}
+	return Pause(ctx, store, input)
+}
+
+func Pause(ctx context.Context, store canvas.Store, input SessionInput) error {
+	_, status, err := loadCanvas(ctx, store, input.CanvasID, canvas.ActionPause)
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetStatus(ctx, input.CanvasID, status); err != nil {
+		slog.Error("Failed to update canvas", "canvasId", input.CanvasID, "error", err)
+		return err
+	}
+
+	slog.Info("Canvas paused", "canvasId", input.CanvasID)
+	return nil
+}
@@ -0,0 +1,34 @@
+package session_router_test
+
+import (
+	"context"
+	"testing"
+
+	session_router "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+)
+
+func TestPause(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+
+	event := session_router.Event{Action: canvas.ActionPause, Data: []byte(`{"canvasId": "c1", "authorId": "admin"}`)}
+	if err := session_router.Route(ctx, store, event); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); c.Status != canvas.StatusPause {
+		t.Fatalf("got status %s, want PAUSE", c.Status)
+	}
+
+	// Pausing twice is refused without being retried.
+	if err := session_router.Route(ctx, store, event); events.KindOf(err) != events.KindRejected {
+		t.Fatalf("Route on paused canvas: got %v, want rejected", err)
+	}
+
+	event.Data = []byte(`{"authorId": "admin"}`)
+	if err := session_router.Route(ctx, store, event); events.KindOf(err) != events.KindInvalid {
+		t.Fatalf("Route without canvas: got %v, want invalid", err)
+	}
+}
@@ -0,0 +1,30 @@
+package session_router
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func handleReset(ctx context.Context, store canvas.Store, event Event) error {
+	input, err := decodeSessionInput(event.Data, event.Action)
+	if err != nil {
+		return err
+	}
+	return Reset(ctx, store, input)
+}
+
+func Reset(ctx context.Context, store canvas.Store, input SessionInput) error {
+	if _, _, err := loadCanvas(ctx, store, input.CanvasID, canvas.ActionReset); err != nil {
+		return err
+	}
+
+	if err := store.DeletePixels(ctx, input.CanvasID); err != nil {
+		slog.Error("Failed to reset pixels", "canvasId", input.CanvasID, "error", err)
+		return err
+	}
+
+	slog.Info("Pixels reset", "canvasId", input.CanvasID)
+	return nil
+}
@@ -0,0 +1,37 @@
+package session_router_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	session_router "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestReset(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	before := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+	_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000"})
+
+	if err := session_router.Reset(ctx, store, session_router.SessionInput{CanvasID: "c1", AuthorID: "admin"}); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if count, _ := store.CountPixels(ctx, "c1"); count != 0 {
+		t.Fatalf("got %d pixels after reset, want 0", count)
+	}
+	c, _ := store.GetCanvas(ctx, "c1")
+	if c.Status != canvas.StatusStart || c.ResetAt.Before(before) {
+		t.Fatalf("unexpected canvas after reset: %+v", c)
+	}
+
+	_ = store.SetStatus(ctx, "c1", canvas.StatusStop)
+	var transitionErr *canvas.TransitionError
+	if err := session_router.Reset(ctx, store, session_router.SessionInput{CanvasID: "c1", AuthorID: "admin"}); !errors.As(err, &transitionErr) {
+		t.Fatalf("Reset on stopped canvas: got %v, want a TransitionError", err)
+	}
+}
@@ -0,0 +1,74 @@
+package session_router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func handleResume(ctx context.Context, store canvas.Store, event Event) error {
+	input, err := decodeSessionInput(event.Data, event.Action)
+	if err != nil {
+		return err
+	}
+
+	c, pixelCount, err := Resume(ctx, store, input)
+	if err != nil {
+		return err
+	}
+
+	if err := FollowUp(ctx, event.InteractionToken, resumedMessage(c, pixelCount)); err != nil {
+		slog.Error("Failed to send canvas state", "canvasId", c.ID, "error", err)
+	}
+	return nil
+}
+
+// Resume moves a paused canvas back to START. Only the status changes: the
+// admin, size, dates and pixels are kept as they were.
+func Resume(ctx context.Context, store canvas.Store, input SessionInput) (*canvas.Canvas, int, error) {
+	c, status, err := loadCanvas(ctx, store, input.CanvasID, canvas.ActionResume)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Reads go first: once the status is written a retry would find the
+	// canvas running and be rejected.
+	pixelCount, err := store.CountPixels(ctx, input.CanvasID)
+	if err != nil {
+		slog.Error("Failed to count pixels", "canvasId", input.CanvasID, "error", err)
+		return nil, 0, err
+	}
+
+	if err := store.SetStatus(ctx, input.CanvasID, status); err != nil {
+		slog.Error("Failed to update canvas", "canvasId", input.CanvasID, "error", err)
+		return nil, 0, err
+	}
+	c.Status = status
+
+	slog.Info("Canvas resumed", "canvasId", input.CanvasID, "resumedBy", input.AuthorID)
+	return c, pixelCount, nil
+}
+
+// resumedMessage shows the canvas state to the admin. Dates use Discord
+// timestamps so they render in the reader's timezone.
+func resumedMessage(c *canvas.Canvas, pixelCount int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** is running again.\n", c.Name)
+	fmt.Fprintf(&b, "Size: %dx%d\n", c.Width, c.Height)
+	fmt.Fprintf(&b, "Started: %s\n", discordTime(c.StartDate))
+	if c.EndDate.IsZero() {
+		b.WriteString("Ends: never\n")
+	} else {
+		fmt.Fprintf(&b, "Ends: %s\n", discordTime(c.EndDate))
+	}
+	fmt.Fprintf(&b, "Pixels painted: %d", pixelCount)
+	return b.String()
+}
+
+func discordTime(t time.Time) string {
+	return fmt.Sprintf("<t:%d:f>", t.Unix())
+}
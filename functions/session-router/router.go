@@ -0,0 +1,173 @@
+package session_router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type MessagePublishedData struct {
+	Message PubSubMessage `json:"message"`
+}
+
+type PubSubMessage struct {
+	Data       []byte            `json:"data"`
+	Attributes map[string]string `json:"attributes"`
+	MessageID  string            `json:"messageId"`
+}
+
+// Event is a session-events message once its attributes are decoded.
+type Event struct {
+	Action           canvas.Action
+	Data             []byte
+	InteractionToken string
+}
+
+type SessionInput struct {
+	CanvasID string `json:"canvasId"`
+	AuthorID string `json:"authorId"`
+}
+
+func decodeSessionInput(data []byte, action canvas.Action) (SessionInput, error) {
+	var input SessionInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		slog.Error("Invalid JSON", "raw", string(data))
+		return input, events.Invalid(err)
+	}
+
+	if input.CanvasID == "" {
+		slog.Error("canvasId is required", "action", action)
+		return input, events.Invalid(errors.New("canvasId is required"))
+	}
+
+	return input, nil
+}
+
+type handler func(ctx context.Context, store canvas.Store, event Event) error
+
+var handlers = map[canvas.Action]handler{
+	canvas.ActionStart:     handleStart,
+	canvas.ActionResume:    handleResume,
+	canvas.ActionPause:     handlePause,
+	canvas.ActionStop:      handleStop,
+	canvas.ActionReset:     handleReset,
+	canvas.ActionConfigure: handleConfigure,
+}
+
+// RejectedError reports an event refused for a reason the user should be
+// told about.
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return "rejected: " + e.Reason
+}
+
+func init() {
+	functions.CloudEvent("RouteSession", events.Consumer("session-router", RouteSession))
+}
+
+func RouteSession(ctx context.Context, e cloudevents.Event) error {
+	var payload MessagePublishedData
+	if err := e.DataAs(&payload); err != nil {
+		slog.Error("Invalid CloudEvent payload", "error", err)
+		return events.Invalid(err)
+	}
+
+	msg := payload.Message
+	parentCtx := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Attributes))
+	tracer := otel.Tracer("RouteSession")
+	ctx, span := tracer.Start(parentCtx, "session-router")
+	defer span.End()
+
+	slog.Info("RouteSession triggered", "data", string(msg.Data), "attributes", msg.Attributes)
+
+	event := Event{
+		Action:           canvas.Action(msg.Attributes["action"]),
+		Data:             msg.Data,
+		InteractionToken: msg.Attributes["discord_interaction_token"],
+	}
+	span.SetAttributes(attribute.String("session.action", string(event.Action)))
+
+	if _, ok := handlers[event.Action]; !ok {
+		slog.Error("Unknown session action", "action", event.Action)
+		return events.Invalid(fmt.Errorf("unknown session action %q", event.Action))
+	}
+
+	store, err := canvas.NewFirestoreStore(ctx, projectID, databaseName)
+	if err != nil {
+		slog.Error("Firestore init failed", "error", err)
+		return err
+	}
+	defer store.Close()
+
+	ran, err := canvas.ProcessOnce(ctx, store, "session-router", msg.MessageID, dedupWindow, func() error {
+		return Route(ctx, store, event)
+	})
+	if err != nil {
+		return err
+	}
+	if !ran {
+		slog.Warn("Duplicate message skipped", "messageId", msg.MessageID, "action", event.Action)
+	}
+	return nil
+}
+
+// Route runs the handler for the event action. Illegal transitions are
+// reported back to the user who sent the command and returned as rejected.
+func Route(ctx context.Context, store canvas.Store, event Event) error {
+	handle, ok := handlers[event.Action]
+	if !ok {
+		slog.Error("Unknown session action", "action", event.Action)
+		return events.Invalid(fmt.Errorf("unknown session action %q", event.Action))
+	}
+
+	err := handle(ctx, store, event)
+
+	var reason string
+	var transitionErr *canvas.TransitionError
+	var rejectedErr *RejectedError
+	switch {
+	case errors.As(err, &transitionErr):
+		reason = transitionErr.Reason
+	case errors.As(err, &rejectedErr):
+		reason = rejectedErr.Reason
+	default:
+		return err
+	}
+
+	slog.Warn("Rejected session event", "action", event.Action, "reason", reason)
+	if err := FollowUp(ctx, event.InteractionToken, "Cannot "+string(event.Action)+": "+reason+"."); err != nil {
+		slog.Error("Failed to notify rejected session event", "error", err)
+	}
+	return events.Rejected(err)
+}
+
+// loadCanvas fetches the canvas targeted by action and checks the action is
+// allowed from its current status. The canvas is nil when it does not exist.
+func loadCanvas(ctx context.Context, store canvas.Store, canvasID string, action canvas.Action) (*canvas.Canvas, canvas.CanvasStatus, error) {
+	c, err := store.GetCanvas(ctx, canvasID)
+	if errors.Is(err, canvas.ErrNotFound) {
+		c = nil
+	} else if err != nil {
+		slog.Error("Failed canvas fetch", "canvasId", canvasID, "error", err)
+		return nil, "", err
+	}
+
+	next, err := canvas.Transition(c, action)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, next, nil
+}
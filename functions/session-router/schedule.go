@@ -0,0 +1,125 @@
+package session_router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel"
+)
+
+// scheduler is the EndedBy of canvases stopped because their EndDate passed.
+const scheduler = "scheduler"
+
+func init() {
+	functions.CloudEvent("ScheduleSessions", events.Consumer("schedule-sessions", ScheduleSessions))
+}
+
+// ScheduleSessions is triggered by a Cloud Scheduler job publishing on a
+// Pub/Sub topic. The message content is ignored.
+func ScheduleSessions(ctx context.Context, e cloudevents.Event) error {
+	tracer := otel.Tracer("ScheduleSessions")
+	ctx, span := tracer.Start(ctx, "schedule-sessions")
+	defer span.End()
+
+	slog.Info("ScheduleSessions triggered", "id", e.ID())
+
+	store, err := canvas.NewFirestoreStore(ctx, projectID, databaseName)
+	if err != nil {
+		slog.Error("Firestore init failed", "error", err)
+		return err
+	}
+	defer store.Close()
+
+	return Schedule(ctx, store, time.Now())
+}
+
+// Schedule opens scheduled canvases whose StartDate passed and stops running
+// or paused canvases whose EndDate passed. It is safe to run again after a
+// partial failure: canvases already moved are skipped by the state machine,
+// and canvases it stopped without requesting their final snapshot are ended
+// again.
+func Schedule(ctx context.Context, store canvas.Store, now time.Time) error {
+	var errs []error
+
+	scheduled, err := store.ListCanvases(ctx, canvas.StatusScheduled)
+	if err != nil {
+		slog.Error("Failed to list scheduled canvases", "error", err)
+		return err
+	}
+	for _, c := range scheduled {
+		if now.Before(c.StartDate) {
+			continue
+		}
+		if err := Open(ctx, store, &c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, status := range []canvas.CanvasStatus{canvas.StatusStart, canvas.StatusPause, canvas.StatusStop} {
+		canvases, err := store.ListCanvases(ctx, status)
+		if err != nil {
+			slog.Error("Failed to list canvases", "status", status, "error", err)
+			return errors.Join(append(errs, err)...)
+		}
+		for _, c := range canvases {
+			if c.EndDate.IsZero() || now.Before(c.EndDate) {
+				continue
+			}
+			if c.Status == canvas.StatusStop && (c.EndedBy != scheduler || !c.FinalSnapshotAt.IsZero()) {
+				continue
+			}
+			if err := End(ctx, store, &c); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Open starts a scheduled canvas and announces it in its channel.
+func Open(ctx context.Context, store canvas.Store, c *canvas.Canvas) error {
+	status, err := canvas.Transition(c, canvas.ActionOpen)
+	if err != nil {
+		slog.Warn("Skipping canvas", "canvasId", c.ID, "error", err)
+		return nil
+	}
+
+	if err := store.SetStatus(ctx, c.ID, status); err != nil {
+		slog.Error("Failed to open canvas", "canvasId", c.ID, "error", err)
+		return err
+	}
+	slog.Info("Canvas opened", "canvasId", c.ID, "startDate", c.StartDate)
+
+	if err := Announce(ctx, c, fmt.Sprintf("**%s** is open, use `/draw` to place your pixels!", c.Name)); err != nil {
+		slog.Error("Failed to announce canvas opening", "canvasId", c.ID, "error", err)
+	}
+	return nil
+}
+
+// End stops a canvas whose EndDate passed and requests its final snapshot,
+// which the snap function posts in the canvas channel with the
+// announcement.
+func End(ctx context.Context, store canvas.Store, c *canvas.Canvas) error {
+	stopped, err := Stop(ctx, store, SessionInput{CanvasID: c.ID, AuthorID: scheduler})
+	var transitionErr *canvas.TransitionError
+	if errors.As(err, &transitionErr) {
+		slog.Warn("Skipping canvas", "canvasId", c.ID, "error", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return RequestFinalSnapshot(ctx, store, stopped, SnapData{
+		AuthorID: stopped.AdminID,
+		Announce: fmt.Sprintf("**%s** has ended with %d pixels painted. Thanks for playing!", stopped.Name, stopped.PixelCount),
+	}, "")
+}
@@ -0,0 +1,185 @@
+package session_router_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	session_router "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+// recordSnaps replaces the snap publisher for the test and returns the
+// payloads it was given.
+func recordSnaps(t *testing.T) *[]session_router.SnapData {
+	t.Helper()
+	var snaps []session_router.SnapData
+	restore := session_router.SetPublishSnap(func(ctx context.Context, msg *pubsub.Message) error {
+		var data session_router.SnapData
+		if err := json.Unmarshal(msg.Data, &data); err != nil {
+			return err
+		}
+		if msg.Attributes["dedup_key"] == "" {
+			t.Errorf("snap message for %s has no dedup key", data.CanvasID)
+		}
+		snaps = append(snaps, data)
+		return nil
+	})
+	t.Cleanup(restore)
+	return &snaps
+}
+
+func TestScheduleOpen(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	snaps := recordSnaps(t)
+	now := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "due", Width: 4, Height: 4, Status: canvas.StatusScheduled, StartDate: now.Add(-time.Minute)})
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "later", Width: 4, Height: 4, Status: canvas.StatusScheduled, StartDate: now.Add(time.Hour)})
+
+	if err := session_router.Schedule(ctx, store, now); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	if c, _ := store.GetCanvas(ctx, "due"); c.Status != canvas.StatusStart {
+		t.Errorf("due canvas is %s, want START", c.Status)
+	}
+	if c, _ := store.GetCanvas(ctx, "later"); c.Status != canvas.StatusScheduled {
+		t.Errorf("later canvas is %s, want SCHEDULED", c.Status)
+	}
+	if len(*snaps) != 0 {
+		t.Errorf("opening requested %d snapshots, want none", len(*snaps))
+	}
+}
+
+func TestScheduleEnd(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	snaps := recordSnaps(t)
+	now := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "running", AdminID: "admin", Name: "Running", Width: 4, Height: 4, Status: canvas.StatusStart, EndDate: now.Add(-time.Minute)})
+	_ = store.PutPixel(ctx, "running", canvas.Pixel{X: 0, Y: 0, Color: "#000000"})
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "paused", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusPause, EndDate: now.Add(-time.Minute)})
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "open", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart, EndDate: now.Add(time.Hour)})
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "endless", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusStart})
+
+	if err := session_router.Schedule(ctx, store, now); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	for _, id := range []string{"running", "paused"} {
+		c, _ := store.GetCanvas(ctx, id)
+		if c.Status != canvas.StatusStop || c.EndedBy != "scheduler" || c.FinalSnapshotAt.IsZero() {
+			t.Errorf("%s canvas was not ended: %+v", id, c)
+		}
+	}
+	for _, id := range []string{"open", "endless"} {
+		if c, _ := store.GetCanvas(ctx, id); c.Status != canvas.StatusStart {
+			t.Errorf("%s canvas is %s, want START", id, c.Status)
+		}
+	}
+
+	if len(*snaps) != 2 {
+		t.Fatalf("got %d snapshot requests, want 2: %+v", len(*snaps), *snaps)
+	}
+	for _, snap := range *snaps {
+		if snap.Mode != "timelapse" || snap.AuthorID != "admin" {
+			t.Errorf("unexpected snap payload: %+v", snap)
+		}
+		if snap.CanvasID == "running" && !strings.Contains(snap.Announce, "**Running** has ended with 1 pixels") {
+			t.Errorf("unexpected announcement: %q", snap.Announce)
+		}
+	}
+}
+
+func TestScheduleOpensThenEnds(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	snaps := recordSnaps(t)
+	now := time.Now()
+
+	// A canvas whose whole session passed between two runs is opened, then
+	// ended in the same run.
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusScheduled, StartDate: now.Add(-time.Hour), EndDate: now.Add(-time.Minute)})
+
+	if err := session_router.Schedule(ctx, store, now); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	c, _ := store.GetCanvas(ctx, "c1")
+	if c.Status != canvas.StatusStop || c.EndedBy != "scheduler" {
+		t.Fatalf("canvas was not opened then ended: %+v", c)
+	}
+	if len(*snaps) != 1 {
+		t.Fatalf("got %d snapshot requests, want 1", len(*snaps))
+	}
+}
+
+func TestScheduleSkipsUserStops(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	snaps := recordSnaps(t)
+	now := time.Now()
+
+	endedAt := now.Add(-time.Hour)
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusStop, EndDate: now.Add(-time.Minute), EndedBy: "admin", EndedAt: endedAt})
+
+	if err := session_router.Schedule(ctx, store, now); err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	c, _ := store.GetCanvas(ctx, "c1")
+	if c.EndedBy != "admin" || !c.EndedAt.Equal(endedAt) || !c.FinalSnapshotAt.IsZero() {
+		t.Errorf("canvas stopped by a user was ended again: %+v", c)
+	}
+	if len(*snaps) != 0 {
+		t.Errorf("got %d snapshot requests, want none", len(*snaps))
+	}
+}
+
+func TestScheduleRetriesEnd(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusStart, EndDate: now.Add(-time.Minute)})
+
+	// The first run stops the canvas but fails to request its snapshot.
+	restore := session_router.SetPublishSnap(func(ctx context.Context, msg *pubsub.Message) error {
+		return errors.New("unavailable")
+	})
+	if err := session_router.Schedule(ctx, store, now); err == nil {
+		t.Fatal("Schedule succeeded, want the publish error")
+	}
+	restore()
+
+	c, _ := store.GetCanvas(ctx, "c1")
+	if c.Status != canvas.StatusStop || !c.FinalSnapshotAt.IsZero() {
+		t.Fatalf("unexpected canvas after the failed run: %+v", c)
+	}
+
+	snaps := recordSnaps(t)
+	if err := session_router.Schedule(ctx, store, now.Add(time.Minute)); err != nil {
+		t.Fatalf("Schedule retry failed: %v", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c1"); c.FinalSnapshotAt.IsZero() {
+		t.Errorf("final snapshot still not requested: %+v", c)
+	}
+	if len(*snaps) != 1 {
+		t.Fatalf("got %d snapshot requests, want 1", len(*snaps))
+	}
+
+	// Once requested, later runs leave the canvas alone.
+	if err := session_router.Schedule(ctx, store, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Schedule after the end failed: %v", err)
+	}
+	if len(*snaps) != 1 {
+		t.Errorf("got %d snapshot requests, want 1", len(*snaps))
+	}
+}
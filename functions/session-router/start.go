@@ -0,0 +1,127 @@
+package session_router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+)
+
+type CanvasInput struct {
+	AdminID   string    `json:"adminId"`
+	CanvasID  string    `json:"canvasId"`
+	ChannelID string    `json:"channelId"`
+	Name      string    `json:"name"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+
+	Cooldown *canvas.CooldownPolicy `json:"cooldown,omitempty"`
+	Palette  *PaletteInput          `json:"palette,omitempty"`
+}
+
+func decodeCanvasInput(data []byte) (CanvasInput, error) {
+	var input CanvasInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		slog.Error("Invalid JSON", "raw", string(data))
+		return input, events.Invalid(err)
+	}
+
+	if input.CanvasID == "" {
+		slog.Error("canvasId must be provided when creating a canvas")
+		return input, events.Invalid(errors.New("canvasId must be provided when creating a canvas"))
+	}
+
+	if input.AdminID == "" || input.Name == "" || input.Width <= 0 || input.Height <= 0 {
+		slog.Error("Missing required fields", "input", input)
+		return input, events.Invalid(errors.New("missing required fields"))
+	}
+
+	if input.StartDate.IsZero() {
+		slog.Error("startDate must be provided when creating a canvas")
+		return input, events.Invalid(errors.New("startDate must be provided when creating a canvas"))
+	}
+
+	if !input.EndDate.IsZero() && !input.EndDate.After(input.StartDate) {
+		slog.Error("endDate must be after startDate", "startDate", input.StartDate, "endDate", input.EndDate)
+		return input, events.Invalid(errors.New("endDate must be after startDate"))
+	}
+
+	if input.Cooldown != nil && (input.Cooldown.Interval < 0 || input.Cooldown.Burst < 1) {
+		slog.Error("Invalid cooldown policy", "cooldown", input.Cooldown)
+		return input, events.Invalid(errors.New("invalid cooldown policy"))
+	}
+
+	return input, nil
+}
+
+func handleStart(ctx context.Context, store canvas.Store, event Event) error {
+	input, err := decodeCanvasInput(event.Data)
+	if err != nil {
+		return err
+	}
+	return Start(ctx, store, input)
+}
+
+// Start creates the canvas. A stopped canvas is replaced by a blank one.
+// Canvases starting in the future stay scheduled until the scheduler opens
+// them.
+func Start(ctx context.Context, store canvas.Store, input CanvasInput) error {
+	existing, status, err := loadCanvas(ctx, store, input.CanvasID, canvas.ActionStart)
+	if err != nil {
+		return err
+	}
+
+	var palette []string
+	if input.Palette != nil {
+		if palette, err = input.Palette.resolve(); err != nil {
+			return err
+		}
+	}
+
+	if status == canvas.StatusStart && input.StartDate.After(time.Now()) {
+		status = canvas.StatusScheduled
+	}
+
+	if existing != nil {
+		if err := store.DeletePixels(ctx, input.CanvasID); err != nil {
+			slog.Error("Failed to clear previous pixels", "canvasId", input.CanvasID, "error", err)
+			return err
+		}
+		// Rollbacks, restores and renderings read the history, they must
+		// not reach into the previous session.
+		if err := store.DeleteSession(ctx, input.CanvasID); err != nil {
+			slog.Error("Failed to clear previous session", "canvasId", input.CanvasID, "error", err)
+			return err
+		}
+	}
+
+	c := canvas.Canvas{
+		ID:        input.CanvasID,
+		AdminID:   input.AdminID,
+		ChannelID: input.ChannelID,
+		Name:      input.Name,
+		Width:     input.Width,
+		Height:    input.Height,
+		Layout:    canvas.LayoutFor(input.Width, input.Height),
+		Status:    status,
+		StartDate: input.StartDate,
+		EndDate:   input.EndDate,
+		Cooldown:  input.Cooldown,
+		Palette:   palette,
+		ResetAt:   time.Now(),
+	}
+
+	if err := store.SaveCanvas(ctx, &c); err != nil {
+		slog.Error("Failed to create canvas", "canvasId", input.CanvasID, "error", err)
+		return err
+	}
+
+	slog.Info("Canvas created", "canvasId", input.CanvasID, "status", status)
+	return nil
+}
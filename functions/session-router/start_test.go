@@ -0,0 +1,84 @@
+package session_router_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	session_router "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func canvasInput(id string) session_router.CanvasInput {
+	return session_router.CanvasInput{
+		AdminID:   "admin",
+		CanvasID:  id,
+		ChannelID: "channel",
+		Name:      "Test",
+		Width:     16,
+		Height:    8,
+		StartDate: time.Now().Add(-time.Minute),
+	}
+}
+
+func TestStart(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	if err := session_router.Start(ctx, store, canvasInput("c1")); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	c, err := store.GetCanvas(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetCanvas failed: %v", err)
+	}
+	if c.Status != canvas.StatusStart || c.Width != 16 || c.Height != 8 || c.Layout != canvas.LayoutPixels {
+		t.Fatalf("unexpected canvas: %+v", c)
+	}
+
+	var transitionErr *canvas.TransitionError
+	if err := session_router.Start(ctx, store, canvasInput("c1")); !errors.As(err, &transitionErr) {
+		t.Fatalf("Start on running canvas: got %v, want a TransitionError", err)
+	}
+
+	input := canvasInput("c2")
+	input.StartDate = time.Now().Add(time.Hour)
+	if err := session_router.Start(ctx, store, input); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if c, _ := store.GetCanvas(ctx, "c2"); c.Status != canvas.StatusScheduled {
+		t.Fatalf("got status %s, want SCHEDULED", c.Status)
+	}
+}
+
+func TestStartStoppedCanvas(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Now()
+
+	if err := session_router.Start(ctx, store, canvasInput("c1")); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{Interval: time.Hour, Burst: 1}); err != nil {
+		t.Fatalf("PlacePixel failed: %v", err)
+	}
+	if _, err := session_router.Stop(ctx, store, session_router.SessionInput{CanvasID: "c1", AuthorID: "admin"}); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+
+	if err := session_router.Start(ctx, store, canvasInput("c1")); err != nil {
+		t.Fatalf("Start on stopped canvas failed: %v", err)
+	}
+
+	if count, _ := store.CountPixels(ctx, "c1"); count != 0 {
+		t.Fatalf("got %d pixels, want a blank canvas", count)
+	}
+	if history, _ := store.AuthorHistory(ctx, "c1", "user"); len(history) != 0 {
+		t.Fatalf("got %d placements from the previous session, want 0", len(history))
+	}
+	// The cooldown of the previous session is forgotten.
+	if err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{Interval: time.Hour, Burst: 1}); err != nil {
+		t.Fatalf("PlacePixel after restart failed: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package session_router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// SnapData mirrors the payload consumed by the snap function.
+type SnapData struct {
+	CanvasID string `json:"canvas_id"`
+	AuthorID string `json:"author_id"`
+	Mode     string `json:"mode,omitempty"`
+	// Posted with the result in the canvas channel, for snapshots no
+	// interaction waits for.
+	Announce string `json:"announce,omitempty"`
+}
+
+func handleStop(ctx context.Context, store canvas.Store, event Event) error {
+	input, err := decodeSessionInput(event.Data, event.Action)
+	if err != nil {
+		return err
+	}
+
+	c, err := Stop(ctx, store, input)
+	if err != nil {
+		return err
+	}
+
+	return RequestFinalSnapshot(ctx, store, c, SnapData{AuthorID: input.AuthorID}, event.InteractionToken)
+}
+
+// Stop finalizes the canvas and records who ended it. A canvas already
+// stopped by the same author whose final snapshot was never requested is
+// stopped again, see canvas.Store.StopCanvas, so a stop failing after the
+// transition can be retried.
+func Stop(ctx context.Context, store canvas.Store, input SessionInput) (*canvas.Canvas, error) {
+	if _, _, err := loadCanvas(ctx, store, input.CanvasID, canvas.ActionStop); err != nil {
+		var transitionErr *canvas.TransitionError
+		if !errors.As(err, &transitionErr) || transitionErr.From != canvas.StatusStop {
+			return nil, err
+		}
+	}
+
+	c, err := store.StopCanvas(ctx, input.CanvasID, input.AuthorID, time.Now())
+	if err != nil {
+		slog.Error("Failed to stop canvas", "canvasId", input.CanvasID, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Canvas stopped", "canvasId", c.ID, "endedBy", c.EndedBy, "pixelCount", c.PixelCount)
+	return c, nil
+}
+
+// publishSnap sends msg to the snap function. Tests replace it to run
+// without Pub/Sub.
+var publishSnap = func(ctx context.Context, msg *pubsub.Message) error {
+	client, err := pubsub.NewClient(ctx, projectID)
+	if err != nil {
+		slog.Error("Failed to create Pub/Sub client", "error", err)
+		return err
+	}
+	defer client.Close()
+
+	publisher := client.Publisher("command.snap")
+	defer publisher.Stop()
+
+	if _, err := publisher.Publish(ctx, msg).Get(ctx); err != nil {
+		slog.Error("Failed to publish snap message", "error", err)
+		return err
+	}
+	return nil
+}
+
+// RequestFinalSnapshot asks the snap function for the final image and
+// timelapse of the canvas, then records it was asked. The Discord
+// interaction token is forwarded so the result replaces the /stop
+// acknowledgement. The message carries a dedup key derived from the stop,
+// so the snap function skips it when a retry publishes it again.
+func RequestFinalSnapshot(ctx context.Context, store canvas.Store, c *canvas.Canvas, data SnapData, interactionToken string) error {
+	data.CanvasID = c.ID
+	data.Mode = "timelapse"
+	body, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("Failed to marshal snap payload", "error", err)
+		return err
+	}
+
+	// Firestore keeps EndedAt to the microsecond, so a resumed stop reading
+	// it back gets the same key at millisecond precision.
+	msg := &pubsub.Message{
+		Data: body,
+		Attributes: map[string]string{
+			"dedup_key": fmt.Sprintf("final_%s_%d", c.ID, c.EndedAt.UnixMilli()),
+		},
+	}
+	if interactionToken != "" {
+		msg.Attributes["discord_interaction_token"] = interactionToken
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Attributes))
+	if err := publishSnap(ctx, msg); err != nil {
+		return err
+	}
+
+	if err := store.SetFinalSnapshotAt(ctx, c.ID, time.Now()); err != nil {
+		slog.Error("Failed to record final snapshot request", "canvasId", c.ID, "error", err)
+		return err
+	}
+
+	slog.Info("Final snapshot requested", "canvasId", c.ID)
+	return nil
+}
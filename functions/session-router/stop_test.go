@@ -0,0 +1,47 @@
+package session_router_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	session_router "github.com/Evan-Lab/cloud-native/functions"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestStop(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 4, Height: 4, Status: canvas.StatusPause})
+	_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000"})
+
+	input := session_router.SessionInput{CanvasID: "c1", AuthorID: "admin"}
+	c, err := session_router.Stop(ctx, store, input)
+	if err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if c.Status != canvas.StatusStop || c.EndedBy != "admin" || c.PixelCount != 1 {
+		t.Fatalf("unexpected canvas: %+v", c)
+	}
+
+	// Until its final snapshot is requested, the stop can be retried.
+	if _, err := session_router.Stop(ctx, store, input); err != nil {
+		t.Fatalf("Stop retry failed: %v", err)
+	}
+
+	var transitionErr *canvas.TransitionError
+	if _, err := session_router.Stop(ctx, store, session_router.SessionInput{CanvasID: "c1", AuthorID: "other"}); !errors.As(err, &transitionErr) {
+		t.Fatalf("Stop by someone else: got %v, want a TransitionError", err)
+	}
+
+	_ = store.SetFinalSnapshotAt(ctx, "c1", time.Now())
+	if _, err := session_router.Stop(ctx, store, input); !errors.As(err, &transitionErr) {
+		t.Fatalf("Stop after the final snapshot: got %v, want a TransitionError", err)
+	}
+
+	if _, err := session_router.Stop(ctx, store, session_router.SessionInput{CanvasID: "missing", AuthorID: "admin"}); !errors.As(err, &transitionErr) {
+		t.Fatalf("Stop on missing canvas: got %v, want a TransitionError", err)
+	}
+}
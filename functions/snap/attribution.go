@@ -0,0 +1,250 @@
+package snap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// topContributors is how many authors the attribution legend lists.
+const topContributors = 8
+
+type AttributionOptions struct {
+	// Highlights the surviving pixels of this user only, in their colors.
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Contributor is an author with pixels still visible on the canvas.
+type Contributor struct {
+	AuthorID string
+	Name     string
+	Pixels   int
+}
+
+// AuthorColor returns the color standing for an author in attribution
+// snapshots. It only depends on the ID, so it stays the same across
+// snapshots and canvases.
+func AuthorColor(authorID string) color.RGBA {
+	// Snowflake IDs of users who joined around the same time share most
+	// digits, a cryptographic hash still spreads them over every hue.
+	sum := sha256.Sum256([]byte(authorID))
+	hue := float64(binary.BigEndian.Uint16(sum[:2])) / (1 << 16) * 6
+	value := 0.75 + float64(sum[2]%4)*0.08
+	const saturation = 0.7
+
+	chroma := value * saturation
+	x := chroma * (1 - math.Abs(math.Mod(hue, 2)-1))
+	var r, g, b float64
+	switch int(hue) {
+	case 0:
+		r, g, b = chroma, x, 0
+	case 1:
+		r, g, b = x, chroma, 0
+	case 2:
+		r, g, b = 0, chroma, x
+	case 3:
+		r, g, b = 0, x, chroma
+	case 4:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	m := value - chroma
+	channel := func(v float64) uint8 { return uint8(math.Round((v + m) * 0xFF)) }
+	return color.RGBA{R: channel(r), G: channel(g), B: channel(b), A: 0xFF}
+}
+
+// SurvivingAuthors replays placements (oldest first) and returns the author
+// of the last placement on each coordinate of a width x height canvas, row
+// by row. Coordinates never drawn on have no author. Chunks do not keep
+// authors, so history is the only place to find them.
+func SurvivingAuthors(placements []canvas.Placement, width, height int) []string {
+	authors := make([]string, width*height)
+	for _, p := range placements {
+		if p.X < 0 || p.Y < 0 || p.X >= width || p.Y >= height {
+			continue
+		}
+		if p.Color == canvas.DefaultColor {
+			// Erasing a pixel leaves nothing to attribute.
+			authors[p.Y*width+p.X] = ""
+			continue
+		}
+		authors[p.Y*width+p.X] = p.AuthorID
+	}
+	return authors
+}
+
+// Contributors counts the surviving pixels of each author, most first.
+func Contributors(authors []string) []Contributor {
+	counts := make(map[string]int)
+	for _, author := range authors {
+		if author != "" {
+			counts[author]++
+		}
+	}
+
+	contributors := make([]Contributor, 0, len(counts))
+	for author, n := range counts {
+		contributors = append(contributors, Contributor{AuthorID: author, Name: author, Pixels: n})
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Pixels != contributors[j].Pixels {
+			return contributors[i].Pixels > contributors[j].Pixels
+		}
+		return contributors[i].AuthorID < contributors[j].AuthorID
+	})
+	return contributors
+}
+
+// RenderAttribution colors each pixel of the canvas bitmap img by its
+// author, or shows only the pixels of opts.UserID in their own colors. It
+// scales the result like BitmapToPng and lists contributors below, they
+// should have their names resolved.
+func RenderAttribution(ctx context.Context, img *image.RGBA, authors []string, contributors []Contributor, regions []canvas.Region, opts AttributionOptions) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "RenderAttribution")
+	defer span.End()
+
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	out := image.NewRGBA(img.Rect)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			author := authors[y*width+x]
+			switch {
+			case author == "" || (opts.UserID != "" && author != opts.UserID):
+				out.SetRGBA(x, y, dim(img.RGBAAt(x, y)))
+			case opts.UserID != "":
+				out.SetRGBA(x, y, img.RGBAAt(x, y))
+			default:
+				out.SetRGBA(x, y, AuthorColor(author))
+			}
+		}
+	}
+
+	scaled, err := ScaleImage(out, 1024)
+	if err != nil {
+		slog.ErrorContext(ctx, "ScaleImage", "error", err)
+		span.RecordError(err)
+		return nil, err
+	}
+	if rgba, ok := scaled.(*image.RGBA); ok {
+		outlineRegions(rgba, regions, width, height)
+	}
+
+	span.SetAttributes(
+		attribute.Int("attribution.contributors", len(contributors)),
+		attribute.String("attribution.user_id", opts.UserID),
+	)
+	return encodePng(ctx, withContributors(scaled, contributors))
+}
+
+// withContributors returns img above one line per contributor, with their
+// color, name and number of pixels.
+func withContributors(img image.Image, contributors []Contributor) *image.RGBA {
+	const (
+		pad    = 6
+		row    = 18
+		swatch = 12
+	)
+	face := basicfont.Face7x13
+	ascent := face.Metrics().Ascent.Ceil()
+
+	bounds := img.Bounds()
+	width := max(bounds.Dx(), minLegendWidth)
+	out := image.NewRGBA(image.Rect(0, 0, width, bounds.Dy()+2*pad+max(len(contributors), 1)*row))
+	draw.Draw(out, out.Bounds(), image.NewUniform(rulerBackground), image.Point{}, draw.Src)
+	draw.Draw(out, bounds.Sub(bounds.Min).Add(image.Pt((width-bounds.Dx())/2, 0)), img, bounds.Min, draw.Src)
+
+	drawer := &font.Drawer{Dst: out, Src: image.NewUniform(rulerInk), Face: face}
+	top := bounds.Dy() + pad
+	if len(contributors) == 0 {
+		drawer.Dot = fixed.P(pad, top+ascent)
+		drawer.DrawString("no pixels")
+	}
+	for i, contributor := range contributors {
+		y := top + i*row
+		box := image.Rect(pad, y, pad+swatch, y+swatch)
+		draw.Draw(out, box, image.NewUniform(AuthorColor(contributor.AuthorID)), image.Point{}, draw.Src)
+
+		drawer.Dot = fixed.P(box.Max.X+pad, y+ascent-1)
+		drawer.DrawString(fmt.Sprintf("%s  %d px", contributor.Name, contributor.Pixels))
+	}
+	return out
+}
+
+// Attribution renders who drew the surviving pixels of c, from the
+// placements made since it was last reset, and uploads it next to the
+// snapshot. It returns the number of pixels the highlighted user has, or
+// every attributed pixel.
+func Attribution(ctx context.Context, store canvas.Store, c *canvas.Canvas, img *image.RGBA, regions []canvas.Region, opts AttributionOptions) (string, int, error) {
+	ctx, span := tracer.Start(ctx, "Attribution")
+	defer span.End()
+
+	placements, err := store.HistoryBetween(ctx, c.ID, c.ResetAt, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "store.HistoryBetween", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", 0, err
+	}
+
+	authors := SurvivingAuthors(placements, c.Width, c.Height)
+	contributors := Contributors(authors)
+
+	pixels := 0
+	for _, contributor := range contributors {
+		if opts.UserID == "" || contributor.AuthorID == opts.UserID {
+			pixels += contributor.Pixels
+		}
+	}
+
+	if opts.UserID != "" {
+		contributors = []Contributor{{AuthorID: opts.UserID, Name: opts.UserID, Pixels: pixels}}
+	} else if len(contributors) > topContributors {
+		contributors = contributors[:topContributors]
+	}
+
+	ids := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		ids = append(ids, contributor.AuthorID)
+	}
+	names := Usernames(ctx, ids)
+	for i := range contributors {
+		if name, ok := names[contributors[i].AuthorID]; ok {
+			contributors[i].Name = name
+		}
+	}
+
+	pngData, err := RenderAttribution(ctx, img, authors, contributors, regions, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "RenderAttribution", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", 0, err
+	}
+
+	name := "attribution"
+	if opts.UserID != "" {
+		name += "_" + opts.UserID
+	}
+	pngUrl, err := UploadPreview(ctx, c, name, pngData)
+	if err != nil {
+		slog.ErrorContext(ctx, "UploadPreview", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", 0, err
+	}
+
+	slog.InfoContext(ctx, "Attribution uploaded", "canvas_id", c.ID, "user_id", opts.UserID, "pixels", pixels)
+	return pngUrl, pixels, nil
+}
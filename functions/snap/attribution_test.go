@@ -0,0 +1,86 @@
+package snap_test
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/functions/snap"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestAuthorColor(t *testing.T) {
+	if snap.AuthorColor("123") != snap.AuthorColor("123") {
+		t.Fatal("an author got two colors")
+	}
+	if snap.AuthorColor("123") == snap.AuthorColor("456") {
+		t.Fatal("two authors got the same color")
+	}
+}
+
+func TestContributors(t *testing.T) {
+	placements := []canvas.Placement{
+		{X: 0, Y: 0, Color: "#FF0000", AuthorID: "alice"},
+		{X: 1, Y: 0, Color: "#FF0000", AuthorID: "alice"},
+		{X: 0, Y: 0, Color: "#00FF00", AuthorID: "bob"},
+		{X: 0, Y: 1, Color: "#00FF00", AuthorID: "bob"},
+		{X: 1, Y: 1, Color: "#0000FF", AuthorID: "carol"},
+		{X: 1, Y: 1, Color: canvas.DefaultColor, AuthorID: "dave"},
+		{X: 5, Y: 5, Color: "#0000FF", AuthorID: "carol"},
+	}
+
+	authors := snap.SurvivingAuthors(placements, 2, 2)
+	want := []string{"bob", "alice", "bob", ""}
+	for i := range want {
+		if authors[i] != want[i] {
+			t.Fatalf("authors = %q, want %q", authors, want)
+		}
+	}
+
+	contributors := snap.Contributors(authors)
+	if len(contributors) != 2 {
+		t.Fatalf("got %d contributors, want 2", len(contributors))
+	}
+	if contributors[0].AuthorID != "bob" || contributors[0].Pixels != 2 {
+		t.Fatalf("first contributor: got %+v, want bob with 2 pixels", contributors[0])
+	}
+	if contributors[1].AuthorID != "alice" || contributors[1].Pixels != 1 {
+		t.Fatalf("second contributor: got %+v, want alice with 1 pixel", contributors[1])
+	}
+}
+
+func TestRenderAttribution(t *testing.T) {
+	ctx := context.Background()
+
+	img := snap.NewBitmap(2, 1)
+	snap.PatchBitmap(ctx, img, []canvas.Pixel{{X: 0, Y: 0, Color: "#FF0000"}, {X: 1, Y: 0, Color: "#00FF00"}})
+	authors := []string{"alice", "bob"}
+	contributors := snap.Contributors(authors)
+
+	data, err := snap.RenderAttribution(ctx, img, authors, contributors, nil, snap.AttributionOptions{})
+	if err != nil {
+		t.Fatalf("RenderAttribution failed: %v", err)
+	}
+	out, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRGB(t, out, 256, 256, snap.AuthorColor("alice"))
+	assertRGB(t, out, 768, 256, snap.AuthorColor("bob"))
+
+	data, err = snap.RenderAttribution(ctx, img, authors, contributors[:1], nil, snap.AttributionOptions{UserID: "bob"})
+	if err != nil {
+		t.Fatalf("RenderAttribution failed: %v", err)
+	}
+	out, err = png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only bob's pixel keeps its color.
+	assertRGB(t, out, 768, 256, color.RGBA{G: 0xFF})
+	if r, g, b, _ := out.At(256, 256).RGBA(); r != g || g != b {
+		t.Fatalf("(256, 256): got %v, want a gray", out.At(256, 256))
+	}
+}
@@ -0,0 +1,195 @@
+package snap
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Each snapshot keeps the bitmap it rendered in cache/canvas_<id>.rgba.gz,
+// the gzipped RGBA pixels of the canvas, with the time its pixels were read
+// in the object metadata. The next snapshot only reads the pixels updated
+// since and patches them in.
+const renderedAtMetadata = "rendered-at"
+
+// cacheOverlap also re-reads the pixels updated just before the bitmap was
+// rendered, their placement may have committed after the pixels were read.
+const cacheOverlap = time.Minute
+
+func bitmapPath(canvasID string) string {
+	return fmt.Sprintf("cache/canvas_%s.rgba.gz", canvasID)
+}
+
+// NewBitmap returns a width x height bitmap painted with canvas.DefaultColor.
+func NewBitmap(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	background, _ := canvas.ParseColor(canvas.DefaultColor)
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = background.R, background.G, background.B, background.A
+	}
+	return img
+}
+
+// PatchBitmap paints pixels on img, skipping those out of its bounds.
+func PatchBitmap(ctx context.Context, img *image.RGBA, pixels []canvas.Pixel) {
+	for _, pixel := range pixels {
+		if !image.Pt(pixel.X, pixel.Y).In(img.Rect) {
+			slog.WarnContext(ctx, "Pixel out of bounds", "x", pixel.X, "y", pixel.Y, "canvas_width", img.Rect.Dx(), "canvas_height", img.Rect.Dy())
+			continue
+		}
+
+		col, err := canvas.ParseColor(pixel.Color)
+		if err != nil {
+			slog.WarnContext(ctx, "Invalid pixel color, rendering the default one", "error", err, "color", pixel.Color, "x", pixel.X, "y", pixel.Y)
+			col, _ = canvas.ParseColor(canvas.DefaultColor)
+		}
+		img.SetRGBA(pixel.X, pixel.Y, col)
+	}
+}
+
+// BitmapPixels lists every pixel of img, row by row, with X, Y and Color set.
+func BitmapPixels(img *image.RGBA) []canvas.Pixel {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	pixels := make([]canvas.Pixel, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			pixels = append(pixels, canvas.Pixel{X: x, Y: y, Color: canvas.Hex(img.RGBAAt(x, y))})
+		}
+	}
+	return pixels
+}
+
+func WriteBitmap(w io.WriteCloser, img *image.RGBA) error {
+	gz := gzip.NewWriter(w)
+	err := errors.Join(
+		func() error { _, err := gz.Write(img.Pix); return err }(),
+		gz.Close(),
+		w.Close(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write bitmap: %w", err)
+	}
+	return nil
+}
+
+// ReadBitmap reads a width x height bitmap written by WriteBitmap.
+func ReadBitmap(r io.Reader, width, height int) (*image.RGBA, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bitmap: %w", err)
+	}
+	defer gz.Close()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if _, err := io.ReadFull(gz, img.Pix); err != nil {
+		return nil, fmt.Errorf("failed to read bitmap: %w", err)
+	}
+	if n, _ := gz.Read(make([]byte, 1)); n > 0 {
+		return nil, fmt.Errorf("bitmap larger than %dx%d", width, height)
+	}
+	return img, nil
+}
+
+// loadBitmap returns the cached bitmap of c and when its pixels were read. It
+// returns a nil bitmap when there is none or when c was reset since.
+func loadBitmap(ctx context.Context, bucket *storage.BucketHandle, c *canvas.Canvas) (*image.RGBA, time.Time, error) {
+	obj := bucket.Object(bitmapPath(c.ID))
+	reader, err := obj.NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer reader.Close()
+
+	attrs, err := obj.Attrs(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	renderedAt, err := time.Parse(time.RFC3339Nano, attrs.Metadata[renderedAtMetadata])
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("bitmap %s: %w", obj.ObjectName(), err)
+	}
+	if !c.ResetAt.Before(renderedAt) {
+		slog.InfoContext(ctx, "Cached bitmap older than the last reset", "canvas_id", c.ID, "rendered_at", renderedAt, "reset_at", c.ResetAt)
+		return nil, time.Time{}, nil
+	}
+
+	img, err := ReadBitmap(reader, c.Width, c.Height)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return img, renderedAt, nil
+}
+
+func saveBitmap(ctx context.Context, bucket *storage.BucketHandle, c *canvas.Canvas, img *image.RGBA, renderedAt time.Time) error {
+	writer := bucket.Object(bitmapPath(c.ID)).NewWriter(ctx)
+	writer.ContentType = "application/gzip"
+	writer.Metadata = map[string]string{renderedAtMetadata: renderedAt.UTC().Format(time.RFC3339Nano)}
+	return WriteBitmap(writer, img)
+}
+
+// Render returns the bitmap of c. It patches the cached one with the pixels
+// updated since it was rendered, and falls back to reading every pixel when
+// there is no usable cache.
+func Render(ctx context.Context, store canvas.Store, c *canvas.Canvas) (*image.RGBA, error) {
+	ctx, span := tracer.Start(ctx, "Render")
+	defer span.End()
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "storage.NewClient", "error", err)
+		span.RecordError(err)
+		return nil, fmt.Errorf("storage.NewClient: %w", err)
+	}
+	defer client.Close()
+	bucket := client.Bucket(bucketName)
+
+	readAt := time.Now()
+	img, renderedAt, err := loadBitmap(ctx, bucket, c)
+	if err != nil {
+		slog.WarnContext(ctx, "Cannot use the cached bitmap, rendering the whole canvas", "error", err, "canvas_id", c.ID)
+		img = nil
+	}
+
+	var pixels []canvas.Pixel
+	if img != nil {
+		pixels, err = store.ListPixelsSince(ctx, c.ID, renderedAt.Add(-cacheOverlap))
+		if err != nil {
+			slog.ErrorContext(ctx, "store.ListPixelsSince", "error", err, "canvas_id", c.ID)
+			span.RecordError(err)
+			return nil, err
+		}
+	} else {
+		img = NewBitmap(c.Width, c.Height)
+		pixels, err = store.ListPixels(ctx, c.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "store.ListPixels", "error", err, "canvas_id", c.ID)
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+	span.SetAttributes(
+		attribute.Bool("render.cached", !renderedAt.IsZero()),
+		attribute.Int("render.pixels_read", len(pixels)),
+	)
+
+	PatchBitmap(ctx, img, pixels)
+
+	if err := saveBitmap(ctx, bucket, c, img, readAt); err != nil {
+		slog.WarnContext(ctx, "Failed to cache the bitmap", "error", err, "canvas_id", c.ID)
+	}
+
+	slog.InfoContext(ctx, "Canvas rendered", "canvas_id", c.ID, "cached", !renderedAt.IsZero(), "pixels_read", len(pixels))
+	return img, nil
+}
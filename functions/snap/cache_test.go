@@ -0,0 +1,66 @@
+package snap_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/functions/snap"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestPatchBitmap(t *testing.T) {
+	ctx := context.Background()
+
+	img := snap.NewBitmap(3, 2)
+	snap.PatchBitmap(ctx, img, []canvas.Pixel{
+		{X: 1, Y: 0, Color: "#FF0000"},
+		{X: 2, Y: 1, Color: "#00FF00"},
+		{X: 3, Y: 0, Color: "#0000FF"},
+	})
+	// A later patch wins, as pixels updated since the cache was rendered do.
+	snap.PatchBitmap(ctx, img, []canvas.Pixel{{X: 1, Y: 0, Color: "#000000"}})
+
+	pixels := snap.BitmapPixels(img)
+	if len(pixels) != 6 {
+		t.Fatalf("got %d pixels, want 6", len(pixels))
+	}
+	want := map[canvas.Point]string{
+		{X: 0, Y: 0}: canvas.DefaultColor,
+		{X: 1, Y: 0}: "#000000",
+		{X: 2, Y: 1}: "#00FF00",
+	}
+	for _, pixel := range pixels {
+		if color, ok := want[canvas.Point{X: pixel.X, Y: pixel.Y}]; ok && pixel.Color != color {
+			t.Fatalf("(%d, %d): got %s, want %s", pixel.X, pixel.Y, pixel.Color, color)
+		}
+	}
+}
+
+func TestBitmapRoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	img := snap.NewBitmap(4, 4)
+	snap.PatchBitmap(ctx, img, []canvas.Pixel{{X: 3, Y: 3, Color: "#123456"}})
+
+	var buf bytes.Buffer
+	if err := snap.WriteBitmap(NoopWriteCloser{Writer: &buf}, img); err != nil {
+		t.Fatalf("WriteBitmap failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	read, err := snap.ReadBitmap(bytes.NewReader(data), 4, 4)
+	if err != nil {
+		t.Fatalf("ReadBitmap failed: %v", err)
+	}
+	if !bytes.Equal(read.Pix, img.Pix) {
+		t.Fatal("bitmap changed through the cache")
+	}
+
+	if _, err := snap.ReadBitmap(bytes.NewReader(data), 8, 8); err == nil {
+		t.Fatal("expected an error reading a smaller bitmap")
+	}
+	if _, err := snap.ReadBitmap(bytes.NewReader(data), 2, 2); err == nil {
+		t.Fatal("expected an error reading a larger bitmap")
+	}
+}
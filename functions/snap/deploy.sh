@@ -1,3 +1,5 @@
+go mod vendor
+
 gcloud run deploy snap-cmd \
   --source . \
   --function SnapCmd \
@@ -6,3 +8,5 @@ gcloud run deploy snap-cmd \
   --service-account=snap-cmd@serverless-epitech-dev-476110.iam.gserviceaccount.com \
   --no-allow-unauthenticated \
   --set-env-vars='GOOGLE_CLOUD_PROJECT=serverless-epitech-dev-476110,SNAPSHOT_BUCKET=dev-rplace-bucket,FIRESTORE_DB=dev-rplace-database,SECRET_MANAGER_ID=458258130383'
+
+rm -rf vendor
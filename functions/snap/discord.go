@@ -9,7 +9,7 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-func RespondToInteraction(ctx context.Context, interaction_token, pngUrl string) error {
+func RespondToInteraction(ctx context.Context, interaction_token string, content string, imageUrls ...string) error {
 	ctx, span := tracer.Start(ctx, "RespondToInteraction")
 	defer span.End()
 
@@ -21,15 +21,18 @@ func RespondToInteraction(ctx context.Context, interaction_token, pngUrl string)
 	}
 	appID := s.State.Application.ID
 
-	embed := &discordgo.MessageEmbed{
-		Image: &discordgo.MessageEmbedImage{
-			URL: pngUrl,
-		},
+	embeds := make([]*discordgo.MessageEmbed, 0, len(imageUrls))
+	for _, url := range imageUrls {
+		embeds = append(embeds, &discordgo.MessageEmbed{
+			Image: &discordgo.MessageEmbedImage{
+				URL: url,
+			},
+		})
 	}
 
 	responseData := discordgo.WebhookEdit{
-		Content: utils.Ptr(""),
-		Embeds:  &[]*discordgo.MessageEmbed{embed},
+		Content: utils.Ptr(content),
+		Embeds:  &embeds,
 	}
 
 	st, err := s.WebhookMessageEdit(appID, interaction_token, "@original", &responseData)
@@ -43,3 +46,70 @@ func RespondToInteraction(ctx context.Context, interaction_token, pngUrl string)
 
 	return nil
 }
+
+// PostToChannel sends content and the images at imageUrls to a channel.
+// Canvases created before their channel was recorded have none and are
+// skipped.
+func PostToChannel(ctx context.Context, channelID string, content string, imageUrls ...string) error {
+	ctx, span := tracer.Start(ctx, "PostToChannel")
+	defer span.End()
+
+	if channelID == "" {
+		slog.WarnContext(ctx, "No channel to post in")
+		return nil
+	}
+
+	s, err := discord.Session()
+	if err != nil {
+		slog.ErrorContext(ctx, "discord.Session", "error", err)
+		span.RecordError(err)
+		return err
+	}
+
+	embeds := make([]*discordgo.MessageEmbed, 0, len(imageUrls))
+	for _, url := range imageUrls {
+		embeds = append(embeds, &discordgo.MessageEmbed{
+			Image: &discordgo.MessageEmbedImage{
+				URL: url,
+			},
+		})
+	}
+
+	st, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Content: content,
+		Embeds:  embeds,
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		slog.ErrorContext(ctx, "ChannelMessageSendComplex", "error", err)
+		span.RecordError(err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "Posted channel message", "message_id", st.ID, "channel_id", st.ChannelID)
+	return nil
+}
+
+// Usernames resolves Discord user IDs to the name shown for them. Users
+// that cannot be fetched are left out.
+func Usernames(ctx context.Context, userIDs []string) map[string]string {
+	ctx, span := tracer.Start(ctx, "Usernames")
+	defer span.End()
+
+	names := make(map[string]string, len(userIDs))
+	s, err := discord.Session()
+	if err != nil {
+		slog.ErrorContext(ctx, "discord.Session", "error", err)
+		span.RecordError(err)
+		return names
+	}
+
+	for _, id := range userIDs {
+		user, err := s.User(id, discordgo.WithContext(ctx))
+		if err != nil {
+			slog.WarnContext(ctx, "Cannot fetch Discord user", "error", err, "user_id", id)
+			continue
+		}
+		names[id] = user.DisplayName()
+	}
+	return names
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"os"
 
-	"cloud.google.com/go/firestore"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
 )
 
 var firestoreDB string
@@ -16,10 +16,6 @@ func init() {
 	}
 }
 
-func Firestore(ctx context.Context) (*firestore.Client, error) {
-	client, err := firestore.NewClientWithDatabase(ctx, projectID, firestoreDB)
-	if err != nil {
-		return nil, err
-	}
-	return client, nil
+func Store(ctx context.Context) (*canvas.FirestoreStore, error) {
+	return canvas.NewFirestoreStore(ctx, projectID, firestoreDB)
 }
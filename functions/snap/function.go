@@ -3,14 +3,19 @@ package snap
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -18,12 +23,33 @@ var (
 )
 
 func init() {
-	functions.CloudEvent("SnapCmd", SnapCmd)
+	functions.CloudEvent("SnapCmd", events.Consumer("snap", SnapCmd))
 }
 
+const (
+	ModeImage     = "image"
+	ModeTimelapse = "timelapse"
+	// ModeRollback previews a moderation rollback without applying it.
+	ModeRollback = "rollback"
+	// ModeHeatmap shows where the canvas changes, for moderators.
+	ModeHeatmap = "heatmap"
+	// ModeAttribution colors the pixels by author.
+	ModeAttribution = "attribution"
+)
+
 type SnapData struct {
-	CanvasID string `json:"canvas_id"`
-	AuthorID string `json:"author_id"`
+	CanvasID    string             `json:"canvas_id"`
+	AuthorID    string             `json:"author_id"`
+	Mode        string             `json:"mode,omitempty"`
+	Regions     bool               `json:"regions,omitempty"`
+	Timelapse   TimelapseOptions   `json:"timelapse"`
+	View        ViewOptions        `json:"view"`
+	Heatmap     HeatmapOptions     `json:"heatmap"`
+	Attribution AttributionOptions `json:"attribution"`
+	Rollback    *RollbackOptions   `json:"rollback,omitempty"`
+	// Posted with the result in the canvas channel when no interaction
+	// waits for it, as for sessions ended by the scheduler.
+	Announce string `json:"announce,omitempty"`
 }
 
 type MessagePublishedData struct {
@@ -40,7 +66,7 @@ func SnapCmd(ctx context.Context, e event.Event) error {
 	var msg MessagePublishedData
 	if err := e.DataAs(&msg); err != nil {
 		slog.ErrorContext(ctx, "event.DataAs", "error", err)
-		return fmt.Errorf("event.DataAs: %w", err)
+		return events.Invalid(fmt.Errorf("event.DataAs: %w", err))
 	}
 	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.Message.Attributes))
 	ctx, span := tracer.Start(ctx, "SnapCmd")
@@ -50,7 +76,7 @@ func SnapCmd(ctx context.Context, e event.Event) error {
 	if err := json.Unmarshal(msg.Message.Data, &payload); err != nil {
 		slog.ErrorContext(ctx, "json.Unmarshal", "error", err, "data", string(msg.Message.Data))
 		span.RecordError(err)
-		return fmt.Errorf("failed to unmarshal SnapData: %w", err)
+		return events.Invalid(fmt.Errorf("failed to unmarshal SnapData: %w", err))
 	}
 	span.SetAttributes(
 		attribute.String("snap.canvas_id", payload.CanvasID),
@@ -59,36 +85,163 @@ func SnapCmd(ctx context.Context, e event.Event) error {
 
 	slog.InfoContext(ctx, "Received SnapCmd event", "canvas_id", payload.CanvasID, "author_id", payload.AuthorID)
 
-	canvas, pixels, err := Snapshot(ctx, &payload)
+	store, err := Store(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Store", "error", err)
+		span.RecordError(err)
+		return fmt.Errorf("Store failed: %w", err)
+	}
+	defer store.Close()
+
+	// Publishers that may send the same request twice, such as the final
+	// snapshot of a stop being retried, set a dedup key standing for it.
+	messageID := msg.Message.MessageID
+	if key := msg.Message.Attributes["dedup_key"]; key != "" {
+		messageID = key
+	}
+
+	ran, err := canvas.ProcessOnce(ctx, store, "snap", messageID, dedupWindow, func() error {
+		return snap(ctx, store, &payload, msg.Message.Attributes)
+	})
+	if err != nil {
+		return err
+	}
+	if !ran {
+		slog.WarnContext(ctx, "Duplicate message skipped", "message_id", messageID)
+	}
+	return nil
+}
+
+// snap renders the snapshot asked for by payload and posts it to the
+// interaction found in the message attributes, or to the canvas channel
+// with payload.Announce.
+func snap(ctx context.Context, store canvas.Store, payload *SnapData, attributes map[string]string) error {
+	span := trace.SpanFromContext(ctx)
+
+	c, img, err := Snapshot(ctx, store, payload)
 	if err != nil {
 		slog.ErrorContext(ctx, "Snapshot", "error", err)
 		span.RecordError(err)
+		if errors.Is(err, canvas.ErrNotFound) {
+			return events.Invalid(fmt.Errorf("Snapshot failed: %w", err))
+		}
 		return fmt.Errorf("Snapshot failed: %w", err)
 	}
 
-	data, err := PixelsToPng(ctx, pixels, canvas.Width, canvas.Height)
+	var view ViewOptions
+	if payload.View.IsSet() {
+		view, err = payload.View.Resolve(c.Width, c.Height)
+		if err != nil {
+			slog.WarnContext(ctx, "Invalid view", "error", err, "view", payload.View)
+			if interaction, ok := attributes["discord_interaction_token"]; ok {
+				if err := RespondToInteraction(ctx, interaction, "Cannot snap: "+err.Error()+"."); err != nil {
+					slog.ErrorContext(ctx, "RespondToInteraction", "error", err)
+				}
+			}
+			return events.Rejected(err)
+		}
+	}
+
+	regions := c.ActiveRegions(time.Now())
+	if !payload.Regions {
+		regions = nil
+	}
+
+	data, err := BitmapToPng(ctx, img, regions)
 	if err != nil {
-		slog.ErrorContext(ctx, "PixelsToPng", "error", err)
+		slog.ErrorContext(ctx, "BitmapToPng", "error", err)
 		span.RecordError(err)
-		return fmt.Errorf("PixelsToPng failed: %w", err)
+		return fmt.Errorf("BitmapToPng failed: %w", err)
 	}
 
-	pngUrl, pixelsUrl, err := UploadSnapshot(ctx, canvas, pixels, data)
+	pngUrl, pixelsUrl, err := UploadSnapshot(ctx, c, BitmapPixels(img), data)
 	if err != nil {
 		slog.ErrorContext(ctx, "UploadSnapshot", "error", err)
 		span.RecordError(err)
 		return fmt.Errorf("UploadSnapshot failed: %w", err)
 	}
 
-	slog.InfoContext(ctx, "Snapshot process completed successfully", "canvas_id", canvas.ID, "png_url", pngUrl, "pixels_url", pixelsUrl)
+	slog.InfoContext(ctx, "Snapshot process completed successfully", "canvas_id", c.ID, "png_url", pngUrl, "pixels_url", pixelsUrl)
+
+	urls := []string{pngUrl}
+	var content string
+	if payload.View.IsSet() {
+		viewData, err := ViewToPng(ctx, img, regions, view)
+		if err != nil {
+			slog.ErrorContext(ctx, "ViewToPng", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("ViewToPng failed: %w", err)
+		}
+		// The full snapshot stays in place for /restore, the view is only
+		// shown to whoever asked.
+		viewUrl, err := UploadPreview(ctx, c, "view", viewData)
+		if err != nil {
+			slog.ErrorContext(ctx, "UploadPreview", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("UploadPreview failed: %w", err)
+		}
+		urls = []string{viewUrl}
+		content = fmt.Sprintf("(%d, %d) to (%d, %d), zoom %dx", view.X, view.Y, view.X+view.Width-1, view.Y+view.Height-1, view.Zoom)
+	}
+
+	if payload.Mode == ModeTimelapse {
+		gifUrl, err := Timelapse(ctx, store, c, payload.Timelapse)
+		if err != nil {
+			slog.ErrorContext(ctx, "Timelapse", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("Timelapse failed: %w", err)
+		}
+		urls = append(urls, gifUrl)
+	}
+
+	if payload.Mode == ModeHeatmap {
+		heatmapUrl, err := Heatmap(ctx, store, c, img, regions, payload.Heatmap)
+		if err != nil {
+			slog.ErrorContext(ctx, "Heatmap", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("Heatmap failed: %w", err)
+		}
+		urls = append(urls, heatmapUrl)
+	}
 
-	if interaction, ok := msg.Message.Attributes["discord_interaction_token"]; ok {
-		if err := RespondToInteraction(ctx, interaction, pngUrl); err != nil {
+	if payload.Mode == ModeAttribution {
+		attributionUrl, pixels, err := Attribution(ctx, store, c, img, regions, payload.Attribution)
+		if err != nil {
+			slog.ErrorContext(ctx, "Attribution", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("Attribution failed: %w", err)
+		}
+		urls = append(urls, attributionUrl)
+		if payload.Attribution.UserID != "" {
+			content = fmt.Sprintf("<@%s> has %d pixels left on the canvas.", payload.Attribution.UserID, pixels)
+		}
+	}
+
+	if payload.Mode == ModeRollback && payload.Rollback != nil {
+		previewUrl, reverted, err := RollbackPreview(ctx, store, c, img, *payload.Rollback)
+		if err != nil {
+			slog.ErrorContext(ctx, "RollbackPreview", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("RollbackPreview failed: %w", err)
+		}
+		urls = append(urls, previewUrl)
+		content = fmt.Sprintf("Dry run: rolling back <@%s> would revert %d pixels, the canvas now and after. Run /rollback with dry_run set to False to apply it.", payload.Rollback.TargetID, reverted)
+	}
+
+	if interaction, ok := attributes["discord_interaction_token"]; ok {
+		if err := RespondToInteraction(ctx, interaction, content, urls...); err != nil {
 			slog.ErrorContext(ctx, "RespondToInteraction", "error", err)
 			span.RecordError(err)
 			return fmt.Errorf("RespondToInteraction failed: %w", err)
 		}
-		slog.InfoContext(ctx, "Responded to Discord interaction", "canvas_id", canvas.ID)
+		slog.InfoContext(ctx, "Responded to Discord interaction", "canvas_id", c.ID)
+	} else if payload.Announce != "" {
+		if err := PostToChannel(ctx, c.ChannelID, payload.Announce, urls...); err != nil {
+			slog.ErrorContext(ctx, "PostToChannel", "error", err)
+			span.RecordError(err)
+			return fmt.Errorf("PostToChannel failed: %w", err)
+		}
+		slog.InfoContext(ctx, "Posted to canvas channel", "canvas_id", c.ID, "channel_id", c.ChannelID)
 	} else {
 		slog.WarnContext(ctx, "No discord_interaction attribute found in Pub/Sub message")
 	}
@@ -1,12 +1,26 @@
 package snap
 
-import "os"
+import (
+	"os"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
 
 var projectID string
 
+// dedupWindow is how long processed message IDs are remembered.
+var dedupWindow time.Duration
+
 func init() {
 	projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
 	if projectID == "" {
 		panic("GOOGLE_CLOUD_PROJECT not set in environment")
 	}
+
+	var err error
+	dedupWindow, err = canvas.ParseDedupWindow(os.Getenv("DEDUP_WINDOW"))
+	if err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,219 @@
+package snap
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// HeatByChanges colors coordinates by how many placements they had.
+	HeatByChanges = "changes"
+	// HeatByRecency colors coordinates by when they last changed.
+	HeatByRecency = "recency"
+
+	legendHeight   = 40
+	minLegendWidth = 256
+)
+
+// heatRamp goes from cold to hot, coordinates never changed are left out
+// and show the canvas dimmed instead.
+var heatRamp = []color.RGBA{
+	{R: 0x2B, G: 0x83, B: 0xBA, A: 0xFF},
+	{R: 0xAB, G: 0xDD, B: 0xA4, A: 0xFF},
+	{R: 0xFF, G: 0xFF, B: 0xBF, A: 0xFF},
+	{R: 0xFD, G: 0xAE, B: 0x61, A: 0xFF},
+	{R: 0xD7, G: 0x19, B: 0x1C, A: 0xFF},
+}
+
+type HeatmapOptions struct {
+	// HeatByChanges, the default, or HeatByRecency.
+	By string `json:"by"`
+}
+
+// heatColor returns the ramp color at t, from 0 (cold) to 1 (hot).
+func heatColor(t float64) color.RGBA {
+	t = min(max(t, 0), 1) * float64(len(heatRamp)-1)
+	i := min(int(t), len(heatRamp)-2)
+	f := t - float64(i)
+	a, b := heatRamp[i], heatRamp[i+1]
+	mix := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*f)) }
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 0xFF}
+}
+
+// dim turns a canvas color into a dark gray, so the drawing stays readable
+// under the heat without being mistaken for it.
+func dim(c color.RGBA) color.RGBA {
+	luma := (299*int(c.R) + 587*int(c.G) + 114*int(c.B)) / 1000
+	v := uint8(0x20 + luma*0x40/0xFF)
+	return color.RGBA{R: v, G: v, B: v, A: 0xFF}
+}
+
+// shortDuration formats d with its largest unit only, as in "3h".
+func shortDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
+
+// RenderHeatmap colors each coordinate of the canvas bitmap img changed by
+// placements (oldest first), by how often or how recently it changed as of
+// now, scales it like BitmapToPng and adds a legend below.
+func RenderHeatmap(ctx context.Context, img *image.RGBA, placements []canvas.Placement, regions []canvas.Region, opts HeatmapOptions, now time.Time) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "RenderHeatmap")
+	defer span.End()
+
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+	counts := make([]int, width*height)
+	last := make([]time.Time, width*height)
+	maxCount := 0
+	for _, p := range placements {
+		if !image.Pt(p.X, p.Y).In(img.Rect) {
+			continue
+		}
+		i := p.Y*width + p.X
+		counts[i]++
+		last[i] = p.PlacedAt
+		maxCount = max(maxCount, counts[i])
+	}
+
+	var oldest time.Time
+	if len(placements) > 0 {
+		oldest = placements[0].PlacedAt
+	}
+	age := max(now.Sub(oldest), time.Minute)
+
+	heat := image.NewRGBA(img.Rect)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			if counts[i] == 0 {
+				heat.SetRGBA(x, y, dim(img.RGBAAt(x, y)))
+				continue
+			}
+			var t float64
+			switch opts.By {
+			case HeatByRecency:
+				t = 1 - float64(now.Sub(last[i]))/float64(age)
+			default:
+				t = 1
+				if maxCount > 1 {
+					t = math.Log(float64(counts[i])) / math.Log(float64(maxCount))
+				}
+			}
+			heat.SetRGBA(x, y, heatColor(t))
+		}
+	}
+
+	scaled, err := ScaleImage(heat, 1024)
+	if err != nil {
+		slog.ErrorContext(ctx, "ScaleImage", "error", err)
+		span.RecordError(err)
+		return nil, err
+	}
+	if rgba, ok := scaled.(*image.RGBA); ok {
+		outlineRegions(rgba, regions, width, height)
+	}
+
+	var title, cold, hot string
+	switch opts.By {
+	case HeatByRecency:
+		title, cold, hot = "last change", shortDuration(age)+" ago", "now"
+	default:
+		title, cold, hot = "changes per pixel", "1", strconv.Itoa(max(maxCount, 1))
+	}
+
+	span.SetAttributes(
+		attribute.String("heatmap.by", opts.By),
+		attribute.Int("heatmap.placements", len(placements)),
+		attribute.Int("heatmap.max_count", maxCount),
+	)
+	return encodePng(ctx, withLegend(scaled, title, cold, hot))
+}
+
+// withLegend returns img above a gradient of heatRamp labelled with title
+// and the values at its cold and hot ends.
+func withLegend(img image.Image, title, cold, hot string) *image.RGBA {
+	const pad = 6
+	face := basicfont.Face7x13
+	ascent := face.Metrics().Ascent.Ceil()
+	labelWidth := func(label string) int { return font.MeasureString(face, label).Ceil() }
+
+	bounds := img.Bounds()
+	width := max(bounds.Dx(), minLegendWidth)
+	out := image.NewRGBA(image.Rect(0, 0, width, bounds.Dy()+legendHeight))
+	draw.Draw(out, out.Bounds(), image.NewUniform(rulerBackground), image.Point{}, draw.Src)
+	draw.Draw(out, bounds.Sub(bounds.Min).Add(image.Pt((width-bounds.Dx())/2, 0)), img, bounds.Min, draw.Src)
+
+	top := bounds.Dy() + pad
+	bar := image.Rect(pad, top, width-pad, top+10)
+	for x := bar.Min.X; x < bar.Max.X; x++ {
+		col := heatColor(float64(x-bar.Min.X) / float64(max(bar.Dx()-1, 1)))
+		draw.Draw(out, image.Rect(x, bar.Min.Y, x+1, bar.Max.Y), image.NewUniform(col), image.Point{}, draw.Src)
+	}
+
+	drawer := &font.Drawer{Dst: out, Src: image.NewUniform(rulerInk), Face: face}
+	baseline := bar.Max.Y + 2 + ascent
+	for _, label := range []struct {
+		text string
+		x    int
+	}{
+		{cold, pad},
+		{title, (width - labelWidth(title)) / 2},
+		{hot, width - pad - labelWidth(hot)},
+	} {
+		drawer.Dot = fixed.P(label.x, baseline)
+		drawer.DrawString(label.text)
+	}
+
+	return out
+}
+
+// Heatmap renders the heatmap of the placements made since c was last reset
+// and uploads it next to the snapshot.
+func Heatmap(ctx context.Context, store canvas.Store, c *canvas.Canvas, img *image.RGBA, regions []canvas.Region, opts HeatmapOptions) (string, error) {
+	ctx, span := tracer.Start(ctx, "Heatmap")
+	defer span.End()
+
+	now := time.Now()
+	placements, err := store.HistoryBetween(ctx, c.ID, c.ResetAt, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "store.HistoryBetween", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	pngData, err := RenderHeatmap(ctx, img, placements, regions, opts, now)
+	if err != nil {
+		slog.ErrorContext(ctx, "RenderHeatmap", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	pngUrl, err := UploadPreview(ctx, c, "heatmap", pngData)
+	if err != nil {
+		slog.ErrorContext(ctx, "UploadPreview", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	slog.InfoContext(ctx, "Heatmap uploaded", "canvas_id", c.ID, "by", opts.By, "placements", len(placements))
+	return pngUrl, nil
+}
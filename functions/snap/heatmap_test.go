@@ -0,0 +1,71 @@
+package snap_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/functions/snap"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func renderHeatmap(t *testing.T, by string) image.Image {
+	t.Helper()
+	ctx := context.Background()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	img := snap.NewBitmap(4, 2)
+	placements := []canvas.Placement{
+		{X: 1, Y: 0, Color: "#00FF00", PlacedAt: now.Add(-time.Hour)},
+		{X: 0, Y: 0, Color: "#FF0000", PlacedAt: now.Add(-50 * time.Minute)},
+		{X: 0, Y: 0, Color: "#00FF00", PlacedAt: now.Add(-40 * time.Minute)},
+		{X: 0, Y: 0, Color: "#0000FF", PlacedAt: now.Add(-30 * time.Minute)},
+		{X: 9, Y: 9, Color: "#0000FF", PlacedAt: now},
+	}
+
+	data, err := snap.RenderHeatmap(ctx, img, placements, nil, snap.HeatmapOptions{By: by}, now)
+	if err != nil {
+		t.Fatalf("RenderHeatmap failed: %v", err)
+	}
+	out, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Scaled to 1024x512, with the legend below.
+	if out.Bounds() != image.Rect(0, 0, 1024, 512+40) {
+		t.Fatalf("got bounds %v", out.Bounds())
+	}
+	return out
+}
+
+func assertRGB(t *testing.T, img image.Image, x, y int, want color.RGBA) {
+	t.Helper()
+	r, g, b, _ := img.At(x, y).RGBA()
+	if uint8(r>>8) != want.R || uint8(g>>8) != want.G || uint8(b>>8) != want.B {
+		t.Fatalf("(%d, %d): got %v, want %v", x, y, img.At(x, y), want)
+	}
+}
+
+func TestRenderHeatmapByChanges(t *testing.T) {
+	out := renderHeatmap(t, snap.HeatByChanges)
+
+	hot := color.RGBA{R: 0xD7, G: 0x19, B: 0x1C}
+	cold := color.RGBA{R: 0x2B, G: 0x83, B: 0xBA}
+	assertRGB(t, out, 128, 128, hot)
+	assertRGB(t, out, 384, 128, cold)
+	// Never changed, the white background dimmed.
+	assertRGB(t, out, 640, 128, color.RGBA{R: 0x60, G: 0x60, B: 0x60})
+}
+
+func TestRenderHeatmapByRecency(t *testing.T) {
+	out := renderHeatmap(t, snap.HeatByRecency)
+
+	// (1, 0) changed first, as long ago as the oldest placement.
+	assertRGB(t, out, 384, 128, color.RGBA{R: 0x2B, G: 0x83, B: 0xBA})
+	// (0, 0) changed halfway, the middle of the ramp.
+	assertRGB(t, out, 128, 128, color.RGBA{R: 0xFF, G: 0xFF, B: 0xBF})
+}
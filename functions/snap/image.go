@@ -3,25 +3,17 @@ package snap
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"image"
 	"image/color"
 	"image/png"
 	"log/slog"
 
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/image/draw"
 )
 
-func HexToColor(hex string) (color.RGBA, error) {
-	var r, g, b uint8
-	_, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b)
-	if err != nil {
-		return color.RGBA{}, err
-	}
-	return color.RGBA{R: r, G: g, B: b, A: 0xFF}, nil
-}
-
 func ScaleImage(src image.Image, size int) (image.Image, error) {
 
 	largestSide := src.Bounds().Dx()
@@ -39,24 +31,44 @@ func ScaleImage(src image.Image, size int) (image.Image, error) {
 	return dst, nil
 }
 
-func PixelsToPng(ctx context.Context, pixels []Pixel, width, height int) ([]byte, error) {
-	ctx, span := tracer.Start(ctx, "PixelsToPng")
-	defer span.End()
-
-	img := image.NewRGBA(image.Rect(0, 0, width, height))
-
-	for _, pixel := range pixels {
-		col, err := HexToColor(pixel.Color)
-		if err != nil {
-			slog.ErrorContext(ctx, "HexToColor", "error", err, "color", pixel.Color)
-			span.RecordError(err)
-			return nil, err
+// regionOutline is drawn around protected regions, a color no preset
+// palette uses.
+var regionOutline = color.RGBA{R: 0xFF, G: 0x00, B: 0xFF, A: 0xFF}
+
+// outlineRegions draws a two pixel border inside each region of a canvas of
+// width x height scaled to img.
+func outlineRegions(img *image.RGBA, regions []canvas.Region, width, height int) {
+	sx := float64(img.Bounds().Dx()) / float64(width)
+	sy := float64(img.Bounds().Dy()) / float64(height)
+
+	for _, r := range regions {
+		outer := image.Rect(int(float64(r.X0)*sx), int(float64(r.Y0)*sy), int(float64(r.X1+1)*sx), int(float64(r.Y1+1)*sy))
+		inner := outer.Inset(2)
+		visible := outer.Intersect(img.Bounds())
+		for y := visible.Min.Y; y < visible.Max.Y; y++ {
+			for x := visible.Min.X; x < visible.Max.X; x++ {
+				if !image.Pt(x, y).In(inner) {
+					img.SetRGBA(x, y, regionOutline)
+				}
+			}
 		}
-		img.Set(pixel.X, pixel.Y, col)
 	}
+}
 
-	dst := image.NewRGBA(image.Rect(0, 0, width, height))
-	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Over, nil)
+// PixelsToPng renders the canvas as a PNG whose largest side is 1024
+// pixels, with regions outlined. Pixels missing from pixels have
+// canvas.DefaultColor.
+func PixelsToPng(ctx context.Context, pixels []canvas.Pixel, width, height int, regions []canvas.Region) ([]byte, error) {
+	img := NewBitmap(width, height)
+	PatchBitmap(ctx, img, pixels)
+	return BitmapToPng(ctx, img, regions)
+}
+
+// BitmapToPng renders a canvas bitmap as a PNG whose largest side is 1024
+// pixels, with regions outlined.
+func BitmapToPng(ctx context.Context, img *image.RGBA, regions []canvas.Region) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "BitmapToPng")
+	defer span.End()
 
 	scaledImg, err := ScaleImage(img, 1024)
 	if err != nil {
@@ -65,8 +77,18 @@ func PixelsToPng(ctx context.Context, pixels []Pixel, width, height int) ([]byte
 		return nil, err
 	}
 
+	if rgba, ok := scaledImg.(*image.RGBA); ok {
+		outlineRegions(rgba, regions, img.Rect.Dx(), img.Rect.Dy())
+	}
+
+	return encodePng(ctx, scaledImg)
+}
+
+func encodePng(ctx context.Context, img image.Image) ([]byte, error) {
+	span := trace.SpanFromContext(ctx)
+
 	var buf bytes.Buffer
-	if err := png.Encode(&buf, scaledImg); err != nil {
+	if err := png.Encode(&buf, img); err != nil {
 		slog.ErrorContext(ctx, "png.Encode", "error", err)
 		span.RecordError(err)
 		return nil, err
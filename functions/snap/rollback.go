@@ -0,0 +1,57 @@
+package snap
+
+import (
+	"context"
+	"image"
+	"log/slog"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// RollbackOptions select the placements a rollback would revert: those
+// TargetID made in [From, To).
+type RollbackOptions struct {
+	TargetID string    `json:"target_id"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+}
+
+// RollbackPreview renders the canvas as it would look once the rollback is
+// applied and returns its URL with the number of pixels reverted. img is the
+// bitmap returned by Snapshot, it is left untouched.
+func RollbackPreview(ctx context.Context, store canvas.Store, c *canvas.Canvas, img *image.RGBA, opts RollbackOptions) (string, int, error) {
+	ctx, span := tracer.Start(ctx, "RollbackPreview")
+	defer span.End()
+
+	placements, err := store.HistoryBetween(ctx, c.ID, opts.From, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "store.HistoryBetween", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", 0, err
+	}
+
+	reverted := c.RollbackPixels(placements, opts.TargetID, opts.From, opts.To)
+	span.SetAttributes(attribute.Int("rollback.reverted", len(reverted)))
+
+	preview := image.NewRGBA(img.Rect)
+	copy(preview.Pix, img.Pix)
+	PatchBitmap(ctx, preview, reverted)
+
+	data, err := BitmapToPng(ctx, preview, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "BitmapToPng", "error", err)
+		span.RecordError(err)
+		return "", 0, err
+	}
+
+	url, err := UploadPreview(ctx, c, "rollback", data)
+	if err != nil {
+		slog.ErrorContext(ctx, "UploadPreview", "error", err)
+		span.RecordError(err)
+		return "", 0, err
+	}
+
+	return url, len(reverted), nil
+}
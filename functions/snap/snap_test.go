@@ -4,20 +4,22 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"image/gif"
 	"io"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/Evan-Lab/cloud-native/functions/snap"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
 )
 
-func buildPixelData(canvas snap.Canvas) []snap.Pixel {
-	pixels := make([]snap.Pixel, 0, canvas.Width*canvas.Height)
-	for y := 0; y < canvas.Height; y++ {
-		for x := 0; x < canvas.Width; x++ {
+func buildPixelData(c canvas.Canvas) []canvas.Pixel {
+	pixels := make([]canvas.Pixel, 0, c.Width*c.Height)
+	for y := 0; y < c.Height; y++ {
+		for x := 0; x < c.Width; x++ {
 			color := fmt.Sprintf("#%06x", rand.Intn(0xFFFFFF+1))
-			pixels = append(pixels, snap.Pixel{
+			pixels = append(pixels, canvas.Pixel{
 				X:         x,
 				Y:         y,
 				Color:     color,
@@ -40,15 +42,15 @@ func (n NoopWriteCloser) Close() error {
 func testSize(t *testing.T, width, height int) {
 	ctx := context.Background()
 
-	canvas := snap.Canvas{
+	c := canvas.Canvas{
 		ID:     fmt.Sprintf("test-canvas-%dx%d", width, height),
 		Width:  width,
 		Height: height,
 	}
 
-	pixels := buildPixelData(canvas)
+	pixels := buildPixelData(c)
 
-	pngData, err := snap.PixelsToPng(ctx, pixels, canvas.Width, canvas.Height)
+	pngData, err := snap.PixelsToPng(ctx, pixels, c.Width, c.Height, nil)
 	if err != nil {
 		t.Fatalf("PixelsToPng failed: %v", err)
 	}
@@ -57,8 +59,8 @@ func testSize(t *testing.T, width, height int) {
 	var buf bytes.Buffer
 	w := NoopWriteCloser{Writer: &buf}
 
-	if err := snap.WritePng(w, pngData); err != nil {
-		t.Fatalf("WritePng failed: %v", err)
+	if err := snap.WriteObject(w, pngData); err != nil {
+		t.Fatalf("WriteObject failed: %v", err)
 	}
 	t.Logf("Written PNG buffer size: %d bytes", buf.Len())
 	if buf.Len() == 0 {
@@ -103,3 +105,54 @@ func Test500x500(t *testing.T) {
 func Test1000x1000(t *testing.T) {
 	testSize(t, 1000, 1000)
 }
+
+func TestTimelapse(t *testing.T) {
+	ctx := context.Background()
+
+	c := canvas.Canvas{ID: "test-canvas-timelapse", Width: 20, Height: 10}
+	start := time.Now().Add(-time.Hour)
+
+	var placements []canvas.Placement
+	for i := 0; i < 500; i++ {
+		placements = append(placements, canvas.Placement{
+			X:        rand.Intn(c.Width),
+			Y:        rand.Intn(c.Height),
+			Color:    fmt.Sprintf("#%06x", rand.Intn(0xFFFFFF+1)),
+			PlacedAt: start.Add(time.Duration(i) * 7 * time.Second),
+		})
+	}
+
+	gifData, err := snap.RenderTimelapse(ctx, &c, placements, snap.TimelapseOptions{FrameInterval: 30, MaxFrames: 20, Scale: 4})
+	if err != nil {
+		t.Fatalf("RenderTimelapse failed: %v", err)
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(gifData))
+	if err != nil {
+		t.Fatalf("gif.DecodeAll failed: %v", err)
+	}
+	if len(anim.Image) == 0 || len(anim.Image) > 20 {
+		t.Fatalf("got %d frames, want between 1 and 20", len(anim.Image))
+	}
+	if b := anim.Image[0].Bounds(); b.Dx() != 80 || b.Dy() != 40 {
+		t.Fatalf("got frame size %dx%d, want 80x40", b.Dx(), b.Dy())
+	}
+}
+
+func TestTimelapseWithoutPlacements(t *testing.T) {
+	ctx := context.Background()
+
+	c := canvas.Canvas{ID: "test-canvas-empty", Width: 20, Height: 10}
+	gifData, err := snap.RenderTimelapse(ctx, &c, nil, snap.TimelapseOptions{Scale: 2})
+	if err != nil {
+		t.Fatalf("RenderTimelapse failed: %v", err)
+	}
+
+	anim, err := gif.DecodeAll(bytes.NewReader(gifData))
+	if err != nil {
+		t.Fatalf("gif.DecodeAll failed: %v", err)
+	}
+	if len(anim.Image) != 1 {
+		t.Fatalf("got %d frames, want a single blank one", len(anim.Image))
+	}
+}
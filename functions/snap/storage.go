@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -51,22 +52,22 @@ func init() {
 // 	return nil
 // }
 
-func WritePng(w io.WriteCloser, pngData []byte) error {
+// WriteObject writes data, a PNG or a GIF, to w and closes it.
+func WriteObject(w io.WriteCloser, data []byte) error {
 	err := errors.Join(
-		func() error { _, err := w.Write(pngData); return err }(),
+		func() error { _, err := w.Write(data); return err }(),
 		w.Close(),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to write PNG data: %w", err)
+		return fmt.Errorf("failed to write object data: %w", err)
 	}
 	return nil
 }
 
-func uploadPng(ctx context.Context, bucket *storage.BucketHandle, canvasID string, pngData []byte) (string, error) {
+func uploadPng(ctx context.Context, bucket *storage.BucketHandle, path string, pngData []byte) (string, error) {
 	ctx, span := tracer.Start(ctx, "uploadPng")
 	defer span.End()
 
-	path := fmt.Sprintf("canvas_%s.png", canvasID)
 	slog.DebugContext(ctx, "Uploading PNG to GCS", "path", path)
 	span.SetAttributes(attribute.String("snapshot.png_path", path))
 
@@ -76,8 +77,8 @@ func uploadPng(ctx context.Context, bucket *storage.BucketHandle, canvasID strin
 
 	writer.ContentType = "image/png"
 
-	if err := WritePng(writer, pngData); err != nil {
-		slog.ErrorContext(ctx, "writePng", "error", err)
+	if err := WriteObject(writer, pngData); err != nil {
+		slog.ErrorContext(ctx, "WriteObject", "error", err)
 		span.RecordError(err)
 		return "", fmt.Errorf("failed to write PNG to GCS: %w", err)
 	}
@@ -95,7 +96,39 @@ func uploadPng(ctx context.Context, bucket *storage.BucketHandle, canvasID strin
 	return url, nil
 }
 
-func WritePixels(w io.WriteCloser, pixels []Pixel) error {
+func uploadGif(ctx context.Context, bucket *storage.BucketHandle, canvasID string, gifData []byte) (string, error) {
+	ctx, span := tracer.Start(ctx, "uploadGif")
+	defer span.End()
+
+	path := fmt.Sprintf("canvas_%s.gif", canvasID)
+	slog.DebugContext(ctx, "Uploading GIF to GCS", "path", path)
+	span.SetAttributes(attribute.String("snapshot.gif_path", path))
+
+	obj := bucket.Object(path)
+	writer := obj.NewWriter(ctx)
+
+	writer.ContentType = "image/gif"
+
+	if err := WriteObject(writer, gifData); err != nil {
+		slog.ErrorContext(ctx, "WriteObject", "error", err)
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to write GIF to GCS: %w", err)
+	}
+
+	url, err := bucket.SignedURL(obj.ObjectName(), &storage.SignedURLOptions{
+		Method:  "GET",
+		Expires: time.Now().Add(24 * time.Hour),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "bucket.SignedURL", "error", err)
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to generate signed URL for GIF: %w", err)
+	}
+
+	return url, nil
+}
+
+func WritePixels(w io.WriteCloser, pixels []canvas.Pixel) error {
 	gz := gzip.NewWriter(w)
 	err := errors.Join(
 		json.NewEncoder(gz).Encode(pixels),
@@ -109,7 +142,7 @@ func WritePixels(w io.WriteCloser, pixels []Pixel) error {
 	return nil
 }
 
-func uploadPixels(ctx context.Context, bucket *storage.BucketHandle, canvasID string, pixels []Pixel) (string, error) {
+func uploadPixels(ctx context.Context, bucket *storage.BucketHandle, canvasID string, pixels []canvas.Pixel) (string, error) {
 	ctx, span := tracer.Start(ctx, "uploadPixels")
 	defer span.End()
 
@@ -142,7 +175,7 @@ func uploadPixels(ctx context.Context, bucket *storage.BucketHandle, canvasID st
 	return url, nil
 }
 
-func UploadSnapshot(ctx context.Context, canvas *Canvas, pixels []Pixel, pngData []byte) (string, string, error) {
+func UploadSnapshot(ctx context.Context, c *canvas.Canvas, pixels []canvas.Pixel, pngData []byte) (string, string, error) {
 	ctx, span := tracer.Start(ctx, "UploadSnapshot")
 	defer span.End()
 
@@ -156,9 +189,41 @@ func UploadSnapshot(ctx context.Context, canvas *Canvas, pixels []Pixel, pngData
 
 	bucket := client.Bucket(bucketName)
 
-	pngUrl, err1 := uploadPng(ctx, bucket, canvas.ID, pngData)
+	pngUrl, err1 := uploadPng(ctx, bucket, fmt.Sprintf("canvas_%s.png", c.ID), pngData)
 
-	pixelsUrl, err2 := uploadPixels(ctx, bucket, canvas.ID, pixels)
+	pixelsUrl, err2 := uploadPixels(ctx, bucket, c.ID, pixels)
 
 	return pngUrl, pixelsUrl, errors.Join(err1, err2)
 }
+
+func UploadTimelapse(ctx context.Context, c *canvas.Canvas, gifData []byte) (string, error) {
+	ctx, span := tracer.Start(ctx, "UploadTimelapse")
+	defer span.End()
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "storage.NewClient", "error", err)
+		span.RecordError(err)
+		return "", fmt.Errorf("storage.NewClient: %w", err)
+	}
+	defer client.Close()
+
+	return uploadGif(ctx, client.Bucket(bucketName), c.ID, gifData)
+}
+
+// UploadPreview stores a rendering that must not replace the canvas
+// snapshot, such as a rollback dry run.
+func UploadPreview(ctx context.Context, c *canvas.Canvas, name string, pngData []byte) (string, error) {
+	ctx, span := tracer.Start(ctx, "UploadPreview")
+	defer span.End()
+
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "storage.NewClient", "error", err)
+		span.RecordError(err)
+		return "", fmt.Errorf("storage.NewClient: %w", err)
+	}
+	defer client.Close()
+
+	return uploadPng(ctx, client.Bucket(bucketName), fmt.Sprintf("%s_%s.png", name, c.ID), pngData)
+}
@@ -0,0 +1,203 @@
+package snap
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"log/slog"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	DefaultFrameInterval = time.Minute
+	DefaultMaxFrames     = 120
+	// Size of the largest side of the GIF when no scale is given.
+	defaultTimelapseSize = 512
+
+	frameDelay     = 10  // 100ms, in 1/100s
+	lastFrameDelay = 300 // hold the final state for 3s
+)
+
+type TimelapseOptions struct {
+	// Canvas time covered by one frame, in seconds.
+	FrameInterval int `json:"frame_interval"`
+	MaxFrames     int `json:"max_frames"`
+	Scale         int `json:"scale"`
+}
+
+func (o TimelapseOptions) withDefaults(width, height int) TimelapseOptions {
+	if o.FrameInterval <= 0 {
+		o.FrameInterval = int(DefaultFrameInterval / time.Second)
+	}
+	if o.MaxFrames <= 0 {
+		o.MaxFrames = DefaultMaxFrames
+	}
+	if o.Scale <= 0 {
+		largestSide := max(width, height, 1)
+		o.Scale = max(defaultTimelapseSize/largestSide, 1)
+	}
+	return o
+}
+
+// timelapsePalette returns a GIF palette holding every color used in the
+// placements. Canvases using more than 256 colors fall back to the web-safe
+// palette and each color is mapped to its nearest entry.
+func timelapsePalette(placements []canvas.Placement) (color.Palette, map[string]uint8) {
+	colors := make(map[string]color.Color)
+	hexes := []string{canvas.DefaultColor}
+	colors[canvas.DefaultColor] = color.White
+	for _, p := range placements {
+		if _, ok := colors[p.Color]; ok {
+			continue
+		}
+		col, err := canvas.ParseColor(p.Color)
+		if err != nil {
+			continue
+		}
+		colors[p.Color] = col
+		hexes = append(hexes, p.Color)
+	}
+
+	indexes := make(map[string]uint8, len(hexes))
+	if len(hexes) > 256 {
+		pal := color.Palette(palette.WebSafe)
+		for _, hex := range hexes {
+			indexes[hex] = uint8(pal.Index(colors[hex]))
+		}
+		return pal, indexes
+	}
+
+	pal := make(color.Palette, 0, len(hexes))
+	for i, hex := range hexes {
+		indexes[hex] = uint8(i)
+		pal = append(pal, colors[hex])
+	}
+	return pal, indexes
+}
+
+func scaleFrame(src *image.Paletted, scale int) *image.Paletted {
+	bounds := src.Bounds()
+	dst := image.NewPaletted(image.Rect(0, 0, bounds.Dx()*scale, bounds.Dy()*scale), src.Palette)
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			dst.Pix[y*dst.Stride+x] = src.Pix[(y/scale)*src.Stride+x/scale]
+		}
+	}
+	return dst
+}
+
+// RenderTimelapse replays placements (oldest first) onto a blank canvas and
+// encodes the result as an animated GIF, one frame per FrameInterval seconds
+// of canvas time. The interval is stretched when needed so the GIF never has
+// more than MaxFrames frames. Without placements it is a single blank frame.
+func RenderTimelapse(ctx context.Context, c *canvas.Canvas, placements []canvas.Placement, opts TimelapseOptions) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "RenderTimelapse")
+	defer span.End()
+
+	opts = opts.withDefaults(c.Width, c.Height)
+
+	var first, last time.Time
+	if len(placements) > 0 {
+		first = placements[0].PlacedAt
+		last = placements[len(placements)-1].PlacedAt
+	}
+	elapsed := last.Sub(first)
+	interval := time.Duration(opts.FrameInterval) * time.Second
+	if steps := time.Duration(opts.MaxFrames - 1); steps == 0 {
+		interval = elapsed + 1
+	} else if elapsed/interval > steps {
+		interval = elapsed/steps + 1
+	}
+
+	pal, indexes := timelapsePalette(placements)
+	background := indexes[canvas.DefaultColor]
+
+	frame := image.NewPaletted(image.Rect(0, 0, c.Width, c.Height), pal)
+	for i := range frame.Pix {
+		frame.Pix[i] = background
+	}
+
+	anim := &gif.GIF{}
+	emit := func() {
+		anim.Image = append(anim.Image, scaleFrame(frame, opts.Scale))
+		anim.Delay = append(anim.Delay, frameDelay)
+	}
+
+	boundary := first.Add(interval)
+	changed := false
+	for _, p := range placements {
+		for !p.PlacedAt.Before(boundary) {
+			if changed {
+				emit()
+				changed = false
+			}
+			boundary = boundary.Add(interval)
+		}
+		if !c.InBounds(p.X, p.Y) {
+			continue
+		}
+		index, ok := indexes[p.Color]
+		if !ok {
+			slog.WarnContext(ctx, "Skipping placement with invalid color", "color", p.Color, "x", p.X, "y", p.Y)
+			continue
+		}
+		frame.Pix[p.Y*frame.Stride+p.X] = index
+		changed = true
+	}
+	if changed || len(anim.Image) == 0 {
+		emit()
+	}
+	anim.Delay[len(anim.Delay)-1] = lastFrameDelay
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		slog.ErrorContext(ctx, "gif.EncodeAll", "error", err)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	gifData := buf.Bytes()
+	slog.InfoContext(ctx, "Timelapse created", "frames", len(anim.Image), "interval", interval, "size_bytes", len(gifData))
+	span.SetAttributes(
+		attribute.Int("timelapse.frames", len(anim.Image)),
+		attribute.Int("timelapse.size_bytes", len(gifData)),
+	)
+
+	return gifData, nil
+}
+
+// Timelapse renders the placements made since c was last reset and uploads
+// the GIF.
+func Timelapse(ctx context.Context, store canvas.Store, c *canvas.Canvas, opts TimelapseOptions) (string, error) {
+	ctx, span := tracer.Start(ctx, "Timelapse")
+	defer span.End()
+
+	placements, err := store.HistoryBetween(ctx, c.ID, c.ResetAt, time.Now())
+	if err != nil {
+		slog.ErrorContext(ctx, "store.HistoryBetween", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	gifData, err := RenderTimelapse(ctx, c, placements, opts)
+	if err != nil {
+		slog.ErrorContext(ctx, "RenderTimelapse", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	gifUrl, err := UploadTimelapse(ctx, c, gifData)
+	if err != nil {
+		slog.ErrorContext(ctx, "UploadTimelapse", "error", err, "canvas_id", c.ID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	return gifUrl, nil
+}
@@ -0,0 +1,209 @@
+package snap
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	// Size of the largest side of a view when no zoom is given.
+	defaultViewSize = 1024
+	// Largest side a view can have, zooms above it are lowered.
+	maxViewSize = 4096
+	// Below this zoom the grid would hide the pixels, so it is not drawn.
+	minGridZoom = 4
+	// Coordinates multiple of this get a stronger grid line.
+	majorGridEvery = 10
+)
+
+var (
+	gridMinor       = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x30}
+	gridMajor       = color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0x80}
+	rulerBackground = color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF}
+	rulerInk        = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xFF}
+)
+
+// ViewOptions pick the part of the canvas a snapshot shows and how. The zero
+// value shows the whole canvas scaled to 1024 pixels, like BitmapToPng.
+type ViewOptions struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	// Zero extends the view to the canvas edge.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Screen pixels per canvas pixel, zero picks the largest fitting 1024.
+	Zoom   int  `json:"zoom"`
+	Grid   bool `json:"grid"`
+	Rulers bool `json:"rulers"`
+}
+
+func (o ViewOptions) IsSet() bool {
+	return o != ViewOptions{}
+}
+
+// Resolve clips the view to a width x height canvas and fills in the
+// defaults. It fails when the view starts outside the canvas.
+func (o ViewOptions) Resolve(width, height int) (ViewOptions, error) {
+	if o.X < 0 || o.Y < 0 || o.X >= width || o.Y >= height {
+		return o, fmt.Errorf("(%d, %d) is outside the %dx%d canvas", o.X, o.Y, width, height)
+	}
+	if o.Width <= 0 || o.X+o.Width > width {
+		o.Width = width - o.X
+	}
+	if o.Height <= 0 || o.Y+o.Height > height {
+		o.Height = height - o.Y
+	}
+
+	largestSide := max(o.Width, o.Height)
+	if o.Zoom <= 0 {
+		o.Zoom = max(defaultViewSize/largestSide, 1)
+	}
+	o.Zoom = min(o.Zoom, max(maxViewSize/largestSide, 1))
+
+	if o.Zoom < minGridZoom {
+		o.Grid = false
+	}
+	return o, nil
+}
+
+// Rect is the part of the canvas shown by a resolved view.
+func (o ViewOptions) Rect() image.Rectangle {
+	return image.Rect(o.X, o.Y, o.X+o.Width, o.Y+o.Height)
+}
+
+// ViewToPng renders the part of a canvas bitmap picked by a view resolved
+// against it, with regions outlined.
+func ViewToPng(ctx context.Context, img *image.RGBA, regions []canvas.Region, o ViewOptions) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "ViewToPng")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("view.x", o.X),
+		attribute.Int("view.y", o.Y),
+		attribute.Int("view.width", o.Width),
+		attribute.Int("view.height", o.Height),
+		attribute.Int("view.zoom", o.Zoom),
+	)
+
+	zoomed := image.NewRGBA(image.Rect(0, 0, o.Width*o.Zoom, o.Height*o.Zoom))
+	draw.NearestNeighbor.Scale(zoomed, zoomed.Bounds(), img, o.Rect(), draw.Src, nil)
+
+	shifted := make([]canvas.Region, 0, len(regions))
+	for _, r := range regions {
+		r.X0, r.Y0, r.X1, r.Y1 = r.X0-o.X, r.Y0-o.Y, r.X1-o.X, r.Y1-o.Y
+		shifted = append(shifted, r)
+	}
+	outlineRegions(zoomed, shifted, o.Width, o.Height)
+
+	if o.Grid {
+		drawGrid(zoomed, o)
+	}
+
+	var out image.Image = zoomed
+	if o.Rulers {
+		out = withRulers(zoomed, o)
+	}
+
+	return encodePng(ctx, out)
+}
+
+// drawGrid draws a line between each pixel, a stronger one every
+// majorGridEvery coordinates.
+func drawGrid(img *image.RGBA, o ViewOptions) {
+	bounds := img.Bounds()
+	for i := 0; i < o.Width; i++ {
+		col := gridMinor
+		if (o.X+i)%majorGridEvery == 0 {
+			col = gridMajor
+		}
+		line := image.Rect(i*o.Zoom, 0, i*o.Zoom+1, bounds.Dy())
+		draw.Draw(img, line, image.NewUniform(col), image.Point{}, draw.Over)
+	}
+	for i := 0; i < o.Height; i++ {
+		col := gridMinor
+		if (o.Y+i)%majorGridEvery == 0 {
+			col = gridMajor
+		}
+		line := image.Rect(0, i*o.Zoom, bounds.Dx(), i*o.Zoom+1)
+		draw.Draw(img, line, image.NewUniform(col), image.Point{}, draw.Over)
+	}
+}
+
+// rulerStep returns the spacing, in canvas pixels, between labelled ticks so
+// labels of labelSize screen pixels do not overlap.
+func rulerStep(zoom, labelSize int) int {
+	for step := 1; ; step *= 10 {
+		for _, n := range []int{1, 2, 5} {
+			if n*step*zoom >= labelSize {
+				return n * step
+			}
+		}
+	}
+}
+
+// withRulers returns img framed by a ruler labelled with canvas coordinates
+// on the top and left edges.
+func withRulers(img *image.RGBA, o ViewOptions) *image.RGBA {
+	const (
+		pad       = 2
+		longTick  = 6
+		shortTick = 3
+	)
+	face := basicfont.Face7x13
+	lineHeight := face.Metrics().Height.Ceil()
+	ascent := face.Metrics().Ascent.Ceil()
+	labelWidth := func(label string) int { return font.MeasureString(face, label).Ceil() }
+
+	widest := labelWidth(strconv.Itoa(max(o.X+o.Width, o.Y+o.Height) - 1))
+	left := widest + longTick + 2*pad
+	top := lineHeight + longTick + 2*pad
+
+	out := image.NewRGBA(image.Rect(0, 0, left+img.Rect.Dx(), top+img.Rect.Dy()))
+	draw.Draw(out, out.Bounds(), image.NewUniform(rulerBackground), image.Point{}, draw.Src)
+	draw.Draw(out, img.Rect.Add(image.Pt(left, top)), img, image.Point{}, draw.Src)
+
+	ink := image.NewUniform(rulerInk)
+	drawer := &font.Drawer{Dst: out, Src: ink, Face: face}
+	step := rulerStep(o.Zoom, max(widest, lineHeight)+2*pad)
+
+	for i := 0; i < o.Width; i++ {
+		coord := o.X + i
+		x := left + i*o.Zoom + o.Zoom/2
+		switch {
+		case coord%step == 0:
+			draw.Draw(out, image.Rect(x, top-longTick, x+1, top), ink, image.Point{}, draw.Src)
+			label := strconv.Itoa(coord)
+			lx := min(max(x-labelWidth(label)/2, left), out.Rect.Dx()-labelWidth(label))
+			drawer.Dot = fixed.P(lx, pad+ascent)
+			drawer.DrawString(label)
+		case o.Zoom >= minGridZoom:
+			draw.Draw(out, image.Rect(x, top-shortTick, x+1, top), ink, image.Point{}, draw.Src)
+		}
+	}
+	for i := 0; i < o.Height; i++ {
+		coord := o.Y + i
+		y := top + i*o.Zoom + o.Zoom/2
+		switch {
+		case coord%step == 0:
+			draw.Draw(out, image.Rect(left-longTick, y, left, y+1), ink, image.Point{}, draw.Src)
+			label := strconv.Itoa(coord)
+			ly := min(max(y-lineHeight/2, top), out.Rect.Dy()-lineHeight)
+			drawer.Dot = fixed.P(left-longTick-pad-labelWidth(label), ly+ascent)
+			drawer.DrawString(label)
+		case o.Zoom >= minGridZoom:
+			draw.Draw(out, image.Rect(left-shortTick, y, left, y+1), ink, image.Point{}, draw.Src)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,82 @@
+package snap_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/functions/snap"
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestViewResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		in   snap.ViewOptions
+		want snap.ViewOptions
+	}{
+		{"whole canvas", snap.ViewOptions{Rulers: true}, snap.ViewOptions{Width: 100, Height: 50, Zoom: 10, Rulers: true}},
+		{"to the edge", snap.ViewOptions{X: 90, Y: 40}, snap.ViewOptions{X: 90, Y: 40, Width: 10, Height: 10, Zoom: 102}},
+		{"clipped", snap.ViewOptions{X: 10, Y: 10, Width: 500, Height: 5, Zoom: 8}, snap.ViewOptions{X: 10, Y: 10, Width: 90, Height: 5, Zoom: 8}},
+		{"zoom capped", snap.ViewOptions{Width: 100, Height: 50, Zoom: 64}, snap.ViewOptions{Width: 100, Height: 50, Zoom: 40}},
+		{"grid needs zoom", snap.ViewOptions{Zoom: 2, Grid: true}, snap.ViewOptions{Width: 100, Height: 50, Zoom: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.in.Resolve(100, 50)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (snap.ViewOptions{X: 100}).Resolve(100, 50); err == nil {
+		t.Fatal("expected an error for a view starting outside the canvas")
+	}
+}
+
+func TestViewToPng(t *testing.T) {
+	ctx := context.Background()
+
+	img := snap.NewBitmap(20, 20)
+	snap.PatchBitmap(ctx, img, []canvas.Pixel{{X: 5, Y: 6, Color: "#FF0000"}})
+
+	view, err := snap.ViewOptions{X: 4, Y: 4, Width: 4, Height: 4, Zoom: 8, Grid: true}.Resolve(20, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := snap.ViewToPng(ctx, img, nil, view)
+	if err != nil {
+		t.Fatalf("ViewToPng failed: %v", err)
+	}
+	out, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Bounds() != image.Rect(0, 0, 32, 32) {
+		t.Fatalf("got bounds %v, want 32x32", out.Bounds())
+	}
+	// (5, 6) is the second column and third row of the view, its center
+	// stays clear of the grid.
+	if r, g, b, _ := out.At(8+4, 16+4).RGBA(); r>>8 != 0xFF || g != 0 || b != 0 {
+		t.Fatalf("got %v, want #FF0000", out.At(12, 20))
+	}
+
+	view.Rulers = true
+	data, err = snap.ViewToPng(ctx, img, nil, view)
+	if err != nil {
+		t.Fatalf("ViewToPng failed: %v", err)
+	}
+	out, err = png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Bounds().Dx() <= 32 || out.Bounds().Dy() <= 32 {
+		t.Fatalf("got bounds %v, want room for the rulers", out.Bounds())
+	}
+}
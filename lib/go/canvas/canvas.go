@@ -0,0 +1,129 @@
+package canvas
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Firestore layout shared by every function:
+//
+//	canvases/{canvasID}
+//	canvases/{canvasID}/pixels/{x}_{y}
+//	canvases/{canvasID}/chunks/{cx}_{cy}
+//	canvases/{canvasID}/rate_limits/{authorID}
+const (
+	CanvasesCollection   = "canvases"
+	PixelsCollection     = "pixels"
+	RateLimitsCollection = "rate_limits"
+)
+
+const DefaultColor = "#FFFFFF"
+
+type CanvasStatus string
+
+const (
+	// StatusScheduled canvases wait for their StartDate to open.
+	StatusScheduled CanvasStatus = "SCHEDULED"
+	StatusStart     CanvasStatus = "START"
+	StatusPause     CanvasStatus = "PAUSE"
+	// StatusStop ends a session. Starting the canvas again opens a blank
+	// one, sharing no pixels, history or cooldowns with the previous one.
+	StatusStop CanvasStatus = "STOP"
+)
+
+type Canvas struct {
+	ID      string       `firestore:"-" json:"id"`
+	AdminID string       `firestore:"AdminID" json:"adminId"`
+	Name    string       `firestore:"Name" json:"name"`
+	Status  CanvasStatus `firestore:"Status" json:"status"`
+	// Discord channel the session runs in, used for announcements.
+	ChannelID string `firestore:"ChannelID,omitempty" json:"channelId,omitempty"`
+
+	Width  int `firestore:"Width" json:"width"`
+	Height int `firestore:"Height" json:"height"`
+	// How pixels are stored, see PixelLayout.
+	Layout PixelLayout `firestore:"Layout,omitempty" json:"layout,omitempty"`
+
+	// Nil means DefaultCooldownPolicy.
+	Cooldown *CooldownPolicy `firestore:"Cooldown,omitempty" json:"cooldown,omitempty"`
+	// Empty means the DefaultPalette preset.
+	Palette []string `firestore:"Palette,omitempty" json:"palette,omitempty"`
+	// Locked areas, see Region.
+	Regions []Region `firestore:"Regions,omitempty" json:"regions,omitempty"`
+	// Authors banned by moderators, they cannot draw anymore.
+	Banned []string `firestore:"Banned,omitempty" json:"banned,omitempty"`
+
+	StartDate time.Time `firestore:"StartDate" json:"startDate"`
+	EndDate   time.Time `firestore:"EndDate" json:"endDate"`
+
+	// Set once the canvas is stopped.
+	EndedBy    string    `firestore:"EndedBy,omitempty" json:"endedBy,omitempty"`
+	EndedAt    time.Time `firestore:"EndedAt,omitempty" json:"endedAt,omitempty"`
+	PixelCount int       `firestore:"PixelCount,omitempty" json:"pixelCount,omitempty"`
+
+	// Set once the final snapshot of the stopped canvas was requested.
+	FinalSnapshotAt time.Time `firestore:"FinalSnapshotAt,omitempty" json:"finalSnapshotAt,omitempty"`
+
+	// Last time the pixels were cleared, renderings made before are stale.
+	ResetAt time.Time `firestore:"ResetAt,omitempty" json:"resetAt,omitempty"`
+}
+
+// OpenAt reports whether t falls inside the drawing window. A zero EndDate
+// leaves the window open.
+func (c *Canvas) OpenAt(t time.Time) bool {
+	if t.Before(c.StartDate) {
+		return false
+	}
+	return c.EndDate.IsZero() || t.Before(c.EndDate)
+}
+
+func (c *Canvas) IsBanned(authorID string) bool {
+	return slices.Contains(c.Banned, authorID)
+}
+
+func (c *Canvas) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.Width && y < c.Height
+}
+
+type Pixel struct {
+	X         int       `firestore:"X" json:"x"`
+	Y         int       `firestore:"Y" json:"y"`
+	Color     string    `firestore:"Color" json:"color"`
+	AuthorID  string    `firestore:"AuthorID" json:"author_id"`
+	UpdatedAt time.Time `firestore:"UpdatedAt" json:"updated_at"`
+
+	// Position of Color in the canvas palette when it was placed.
+	ColorIndex int `firestore:"ColorIndex" json:"color_index"`
+}
+
+// RateLimit is the cooldown bucket of one author on one canvas. Tokens is
+// the bucket level right after the placement made at UpdatedAt.
+type RateLimit struct {
+	UpdatedAt time.Time `firestore:"updatedAt"`
+	Tokens    float64   `firestore:"tokens"`
+}
+
+func CanvasPath(canvasID string) string {
+	return fmt.Sprintf("%s/%s", CanvasesCollection, canvasID)
+}
+
+func PixelDocID(x, y int) string {
+	return fmt.Sprintf("%d_%d", x, y)
+}
+
+func PixelsPath(canvasID string) string {
+	return fmt.Sprintf("%s/%s", CanvasPath(canvasID), PixelsCollection)
+}
+
+func PixelPath(canvasID string, x, y int) string {
+	return fmt.Sprintf("%s/%s", PixelsPath(canvasID), PixelDocID(x, y))
+}
+
+func RateLimitsPath(canvasID string) string {
+	return fmt.Sprintf("%s/%s", CanvasPath(canvasID), RateLimitsCollection)
+}
+
+func RateLimitPath(canvasID, authorID string) string {
+	return fmt.Sprintf("%s/%s", RateLimitsPath(canvasID), authorID)
+}
@@ -0,0 +1,220 @@
+package canvas
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// PixelLayout is how the pixels of a canvas are stored in Firestore.
+type PixelLayout string
+
+const (
+	// LayoutPixels stores one document per pixel, in
+	// canvases/{canvasID}/pixels/{x}_{y}.
+	LayoutPixels PixelLayout = ""
+	// LayoutChunks packs ChunkSize x ChunkSize pixels per document, in
+	// canvases/{canvasID}/chunks/{cx}_{cy}. Reads and resets touch a few
+	// hundred documents instead of one per pixel, at the cost of
+	// placements in the same chunk contending on one document.
+	LayoutChunks PixelLayout = "chunks"
+)
+
+const (
+	ChunksCollection = "chunks"
+	ChunkSize        = 64
+	// maxChunkColors keeps chunk indexes in a byte.
+	maxChunkColors = 256
+	// ChunkedArea is the number of pixels above which canvases use
+	// LayoutChunks.
+	ChunkedArea = 256 * 256
+)
+
+// LayoutFor returns the layout of a new width x height canvas. Only large
+// canvases are chunked, others keep the author and time of each pixel.
+func LayoutFor(width, height int) PixelLayout {
+	if width*height > ChunkedArea {
+		return LayoutChunks
+	}
+	return LayoutPixels
+}
+
+// Chunk holds the pixels of a ChunkSize x ChunkSize square. Indexes holds,
+// row by row, the position of each pixel color in Colors. Colors is local
+// to the chunk so changing the canvas palette does not recolor placed
+// pixels. Colors[0] is DefaultColor, the color of pixels never drawn;
+// pixels drawn with DefaultColor are not told apart from them.
+type Chunk struct {
+	CX        int       `firestore:"CX"`
+	CY        int       `firestore:"CY"`
+	Colors    []string  `firestore:"Colors"`
+	Indexes   []byte    `firestore:"Indexes"`
+	UpdatedAt time.Time `firestore:"UpdatedAt"`
+}
+
+// ChunkOf returns the coordinates of the chunk holding (x, y).
+func ChunkOf(x, y int) (int, int) {
+	return x / ChunkSize, y / ChunkSize
+}
+
+func NewChunk(cx, cy int) *Chunk {
+	return &Chunk{
+		CX:      cx,
+		CY:      cy,
+		Colors:  []string{DefaultColor},
+		Indexes: make([]byte, ChunkSize*ChunkSize),
+	}
+}
+
+func ChunkDocID(cx, cy int) string {
+	return fmt.Sprintf("%d_%d", cx, cy)
+}
+
+func ChunksPath(canvasID string) string {
+	return fmt.Sprintf("%s/%s", CanvasPath(canvasID), ChunksCollection)
+}
+
+func ChunkPath(canvasID string, cx, cy int) string {
+	return fmt.Sprintf("%s/%s", ChunksPath(canvasID), ChunkDocID(cx, cy))
+}
+
+// offset returns the position of (x, y), in canvas coordinates, in Indexes.
+func (ch *Chunk) offset(x, y int) int {
+	return (y-ch.CY*ChunkSize)*ChunkSize + (x - ch.CX*ChunkSize)
+}
+
+// Color returns the color of (x, y), in canvas coordinates.
+func (ch *Chunk) Color(x, y int) string {
+	return ch.Colors[ch.Indexes[ch.offset(x, y)]]
+}
+
+// Set paints (x, y), in canvas coordinates. Colors no pixel uses anymore are
+// dropped when the color table is full.
+func (ch *Chunk) Set(x, y int, color string) error {
+	index := -1
+	for i, c := range ch.Colors {
+		if c == color {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		if len(ch.Colors) == maxChunkColors {
+			ch.compact()
+		}
+		if len(ch.Colors) == maxChunkColors {
+			return fmt.Errorf("chunk %s holds %d colors already", ChunkDocID(ch.CX, ch.CY), maxChunkColors)
+		}
+		index = len(ch.Colors)
+		ch.Colors = append(ch.Colors, color)
+	}
+
+	ch.Indexes[ch.offset(x, y)] = byte(index)
+	return nil
+}
+
+// compact drops the colors no pixel uses, keeping DefaultColor first.
+func (ch *Chunk) compact() {
+	used := make([]bool, len(ch.Colors))
+	used[0] = true
+	for _, index := range ch.Indexes {
+		used[index] = true
+	}
+
+	remap := make([]byte, len(ch.Colors))
+	colors := ch.Colors[:0]
+	for i, color := range ch.Colors {
+		if used[i] {
+			remap[i] = byte(len(colors))
+			colors = append(colors, color)
+		}
+	}
+	ch.Colors = colors
+
+	for i, index := range ch.Indexes {
+		ch.Indexes[i] = remap[index]
+	}
+}
+
+// Pixels returns the drawn pixels of the chunk, with X, Y and Color set.
+func (ch *Chunk) Pixels() []Pixel {
+	var pixels []Pixel
+	for i, index := range ch.Indexes {
+		if index == 0 {
+			continue
+		}
+		pixels = append(pixels, Pixel{
+			X:     ch.CX*ChunkSize + i%ChunkSize,
+			Y:     ch.CY*ChunkSize + i/ChunkSize,
+			Color: ch.Colors[index],
+		})
+	}
+	return pixels
+}
+
+// Area returns every pixel of the chunk, with X, Y and Color set, those
+// never drawn with DefaultColor.
+func (ch *Chunk) Area() []Pixel {
+	pixels := make([]Pixel, len(ch.Indexes))
+	for i, index := range ch.Indexes {
+		pixels[i] = Pixel{
+			X:     ch.CX*ChunkSize + i%ChunkSize,
+			Y:     ch.CY*ChunkSize + i/ChunkSize,
+			Color: ch.Colors[index],
+		}
+	}
+	return pixels
+}
+
+// validate reports chunks whose documents were not written by Set.
+func (ch *Chunk) validate() error {
+	if len(ch.Indexes) != ChunkSize*ChunkSize {
+		return fmt.Errorf("chunk %s has %d indexes", ChunkDocID(ch.CX, ch.CY), len(ch.Indexes))
+	}
+	if len(ch.Colors) == 0 || len(ch.Colors) > maxChunkColors {
+		return fmt.Errorf("chunk %s has %d colors", ChunkDocID(ch.CX, ch.CY), len(ch.Colors))
+	}
+	for _, index := range ch.Indexes {
+		if int(index) >= len(ch.Colors) {
+			return fmt.Errorf("chunk %s has index %d out of its colors", ChunkDocID(ch.CX, ch.CY), index)
+		}
+	}
+	return nil
+}
+
+// ChunkPixels packs pixels into the chunks holding them, ordered by CY then
+// CX. Later pixels win over earlier ones at the same coordinates.
+func ChunkPixels(pixels []Pixel) ([]*Chunk, error) {
+	byCoords := make(map[Point]*Chunk)
+	for _, pixel := range pixels {
+		if pixel.X < 0 || pixel.Y < 0 {
+			return nil, fmt.Errorf("pixel (%d, %d) out of bounds", pixel.X, pixel.Y)
+		}
+
+		cx, cy := ChunkOf(pixel.X, pixel.Y)
+		ch, ok := byCoords[Point{X: cx, Y: cy}]
+		if !ok {
+			ch = NewChunk(cx, cy)
+			byCoords[Point{X: cx, Y: cy}] = ch
+		}
+		if err := ch.Set(pixel.X, pixel.Y, pixel.Color); err != nil {
+			return nil, err
+		}
+		if pixel.UpdatedAt.After(ch.UpdatedAt) {
+			ch.UpdatedAt = pixel.UpdatedAt
+		}
+	}
+
+	chunks := make([]*Chunk, 0, len(byCoords))
+	for _, ch := range byCoords {
+		chunks = append(chunks, ch)
+	}
+	slices.SortFunc(chunks, func(a, b *Chunk) int {
+		if a.CY != b.CY {
+			return a.CY - b.CY
+		}
+		return a.CX - b.CX
+	})
+	return chunks, nil
+}
@@ -0,0 +1,135 @@
+package canvas_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestChunkSet(t *testing.T) {
+	cx, cy := canvas.ChunkOf(130, 70)
+	if cx != 2 || cy != 1 {
+		t.Fatalf("ChunkOf(130, 70) = %d, %d, want 2, 1", cx, cy)
+	}
+
+	ch := canvas.NewChunk(cx, cy)
+	if got := ch.Color(130, 70); got != canvas.DefaultColor {
+		t.Fatalf("unset pixel: got %s, want %s", got, canvas.DefaultColor)
+	}
+
+	for _, p := range []canvas.Pixel{
+		{X: 130, Y: 70, Color: "#FF0000"},
+		{X: 191, Y: 127, Color: "#00FF00"},
+		{X: 128, Y: 64, Color: "#FF0000"},
+	} {
+		if err := ch.Set(p.X, p.Y, p.Color); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := ch.Color(191, 127); got != "#00FF00" {
+		t.Fatalf("(191, 127): got %s, want #00FF00", got)
+	}
+	if len(ch.Colors) != 3 {
+		t.Fatalf("got %d colors, want 3", len(ch.Colors))
+	}
+
+	pixels := ch.Pixels()
+	want := []canvas.Pixel{
+		{X: 128, Y: 64, Color: "#FF0000"},
+		{X: 130, Y: 70, Color: "#FF0000"},
+		{X: 191, Y: 127, Color: "#00FF00"},
+	}
+	if len(pixels) != len(want) {
+		t.Fatalf("got %d pixels, want %d", len(pixels), len(want))
+	}
+	for i := range want {
+		if pixels[i] != want[i] {
+			t.Fatalf("pixel %d: got %+v, want %+v", i, pixels[i], want[i])
+		}
+	}
+}
+
+func TestChunkSetDropsUnusedColors(t *testing.T) {
+	ch := canvas.NewChunk(0, 0)
+
+	// Repaint one pixel with 300 colors, only the last one stays in use.
+	for i := range 300 {
+		if err := ch.Set(0, 0, fmt.Sprintf("#%06X", i)); err != nil {
+			t.Fatalf("color %d: %v", i, err)
+		}
+	}
+	if got := ch.Color(0, 0); got != "#00012B" {
+		t.Fatalf("got %s, want #00012B", got)
+	}
+	if ch.Colors[0] != canvas.DefaultColor {
+		t.Fatalf("Colors[0] = %s, want %s", ch.Colors[0], canvas.DefaultColor)
+	}
+
+	full := canvas.NewChunk(0, 0)
+	for i := range 255 {
+		if err := full.Set(i%canvas.ChunkSize, i/canvas.ChunkSize, fmt.Sprintf("#%06X", i)); err != nil {
+			t.Fatalf("color %d: %v", i, err)
+		}
+	}
+	if err := full.Set(63, 63, "#ABCDEF"); err == nil {
+		t.Fatal("expected an error once 256 colors are in use")
+	}
+}
+
+func TestChunkPixels(t *testing.T) {
+	chunks, err := canvas.ChunkPixels([]canvas.Pixel{
+		{X: 70, Y: 0, Color: "#FF0000"},
+		{X: 0, Y: 64, Color: "#00FF00"},
+		{X: 1, Y: 1, Color: "#0000FF"},
+		{X: 70, Y: 0, Color: "#000000"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+	for i, want := range []canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}} {
+		if chunks[i].CX != want.X || chunks[i].CY != want.Y {
+			t.Fatalf("chunk %d: got (%d, %d), want %v", i, chunks[i].CX, chunks[i].CY, want)
+		}
+	}
+	if got := chunks[1].Color(70, 0); got != "#000000" {
+		t.Fatalf("(70, 0): got %s, want #000000", got)
+	}
+
+	if _, err := canvas.ChunkPixels([]canvas.Pixel{{X: -1, Y: 0, Color: "#000000"}}); err == nil {
+		t.Fatal("expected an error for negative coordinates")
+	}
+}
+
+func TestChunkArea(t *testing.T) {
+	ch := canvas.NewChunk(1, 0)
+	_ = ch.Set(65, 2, "#FF0000")
+
+	area := ch.Area()
+	if len(area) != canvas.ChunkSize*canvas.ChunkSize {
+		t.Fatalf("got %d pixels, want %d", len(area), canvas.ChunkSize*canvas.ChunkSize)
+	}
+	if area[0].X != 64 || area[0].Y != 0 || area[0].Color != canvas.DefaultColor {
+		t.Fatalf("first pixel: got %+v, want (64, 0) %s", area[0], canvas.DefaultColor)
+	}
+	if p := area[2*canvas.ChunkSize+1]; p.X != 65 || p.Y != 2 || p.Color != "#FF0000" {
+		t.Fatalf("got %+v, want (65, 2) #FF0000", p)
+	}
+}
+
+func TestLayoutFor(t *testing.T) {
+	if got := canvas.LayoutFor(100, 100); got != canvas.LayoutPixels {
+		t.Fatalf("LayoutFor(100, 100) = %q, want pixels", got)
+	}
+	if got := canvas.LayoutFor(256, 256); got != canvas.LayoutPixels {
+		t.Fatalf("LayoutFor(256, 256) = %q, want pixels", got)
+	}
+	if got := canvas.LayoutFor(1000, 1000); got != canvas.LayoutChunks {
+		t.Fatalf("LayoutFor(1000, 1000) = %q, want chunks", got)
+	}
+}
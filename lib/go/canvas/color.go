@@ -0,0 +1,102 @@
+package canvas
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/colornames"
+)
+
+// ParseColor reads a color written as #RGB, #RRGGBB, the same without the
+// hash, rgb(r, g, b) or a CSS color name.
+func ParseColor(s string) (color.RGBA, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+
+	if args, ok := strings.CutPrefix(text, "rgb("); ok {
+		return parseRGB(s, strings.TrimSuffix(args, ")"))
+	}
+
+	if c, ok := colornames.Map[colorName(text)]; ok {
+		return c, nil
+	}
+
+	hex := strings.TrimPrefix(text, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		if v, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, nil
+		}
+	}
+
+	return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+}
+
+func parseRGB(s, args string) (color.RGBA, error) {
+	parts := strings.Split(args, ",")
+	if len(parts) != 3 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, want rgb(r, g, b)", s)
+	}
+
+	var channels [3]uint8
+	for i, part := range parts {
+		v, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid color %q, channels go from 0 to 255", s)
+		}
+		channels[i] = uint8(v)
+	}
+	return color.RGBA{R: channels[0], G: channels[1], B: channels[2], A: 0xFF}, nil
+}
+
+// Hex formats c as an uppercase #RRGGBB string, the form pixels are stored
+// in.
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+// NormalizeHex returns color as an uppercase #RRGGBB string.
+func NormalizeHex(s string) (string, error) {
+	c, err := ParseColor(s)
+	if err != nil {
+		return "", err
+	}
+	return Hex(c), nil
+}
+
+// colorName folds the spellings of a color name: "Light Grey",
+// "light-gray" and "lightgray" are the same color.
+func colorName(s string) string {
+	name := strings.ToLower(s)
+	name = strings.NewReplacer(" ", "", "-", "", "_", "").Replace(name)
+	return strings.ReplaceAll(name, "grey", "gray")
+}
+
+// NormalizeColor turns player input into the form sent to DrawPixel: a
+// palette index, a palette color name, or #RRGGBB. Indexes and names are
+// kept as is because only the canvas knows its palette. Numbers of up to
+// three digits are indexes, write them with a hash to mean a color.
+func NormalizeColor(s string) (string, error) {
+	text := strings.TrimSpace(s)
+
+	if index, ok := parseIndex(text); ok {
+		return strconv.Itoa(index), nil
+	}
+
+	if name := colorName(text); isPaletteName(name) {
+		return name, nil
+	}
+
+	return NormalizeHex(text)
+}
+
+func parseIndex(s string) (int, bool) {
+	if len(s) == 0 || len(s) > 3 || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+	index, err := strconv.Atoi(s)
+	return index, err == nil
+}
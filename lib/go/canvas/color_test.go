@@ -0,0 +1,38 @@
+package canvas_test
+
+import (
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestNormalizeColor(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"#ff5733", "#FF5733"},
+		{"FF5733", "#FF5733"},
+		{"#f53", "#FF5533"},
+		{"abc", "#AABBCC"},
+		{"rgb(255, 87,51)", "#FF5733"},
+		{"RGB(0,0,0)", "#000000"},
+		{"CornflowerBlue", "#6495ED"},
+		{"Light Grey", "lightgray"},
+		{"red", "red"},
+		{"007", "7"},
+		{"#123", "#112233"},
+	}
+	for _, tt := range tests {
+		got, err := canvas.NormalizeColor(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeColor(%q): got %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "#12345", "rgb(1,2)", "rgb(300,0,0)", "notacolor", "#GGGGGG"} {
+		if got, err := canvas.NormalizeColor(input); err == nil {
+			t.Errorf("NormalizeColor(%q): got %q, want error", input, got)
+		}
+	}
+}
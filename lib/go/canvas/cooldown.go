@@ -0,0 +1,72 @@
+package canvas
+
+import "time"
+
+// DefaultCooldownPolicy applies to canvases created without a policy.
+var DefaultCooldownPolicy = CooldownPolicy{Interval: 35, Burst: 1}
+
+// CooldownPolicy is stored on the canvas and tuned by its admin. Intervals
+// are in seconds.
+type CooldownPolicy struct {
+	// Time to earn back one pixel once the burst is spent.
+	Interval int `firestore:"Interval" json:"interval"`
+	// Pixels that can be placed back to back after a rest.
+	Burst int `firestore:"Burst" json:"burst"`
+	// Interval overrides keyed by Discord role ID. Members with several
+	// overridden roles get the shortest one.
+	RoleIntervals map[string]int `firestore:"RoleIntervals,omitempty" json:"roleIntervals,omitempty"`
+}
+
+// Cooldown is the token bucket applied to one placement: Burst pixels can
+// be placed back to back, then one more every Interval. A zero Interval
+// disables the check.
+type Cooldown struct {
+	Interval time.Duration
+	Burst    int
+}
+
+// CooldownFor returns the cooldown of a member holding roles. The canvas
+// admin is never limited.
+func (c *Canvas) CooldownFor(authorID string, roles []string) Cooldown {
+	if authorID == c.AdminID {
+		return Cooldown{}
+	}
+
+	policy := DefaultCooldownPolicy
+	if c.Cooldown != nil {
+		policy = *c.Cooldown
+	}
+
+	interval := policy.Interval
+	for _, role := range roles {
+		if override, ok := policy.RoleIntervals[role]; ok && override < interval {
+			interval = override
+		}
+	}
+
+	return Cooldown{
+		Interval: time.Duration(interval) * time.Second,
+		Burst:    max(policy.Burst, 1),
+	}
+}
+
+// take refills the bucket for the time elapsed since the last placement and
+// spends one token at now. A nil limit is a full bucket.
+func (cd Cooldown) take(limit *RateLimit, now time.Time) (RateLimit, error) {
+	if cd.Interval <= 0 {
+		return RateLimit{UpdatedAt: now}, nil
+	}
+
+	burst := float64(max(cd.Burst, 1))
+	tokens := burst
+	if limit != nil {
+		elapsed := max(now.Sub(limit.UpdatedAt), 0)
+		tokens = min(burst, limit.Tokens+float64(elapsed)/float64(cd.Interval))
+	}
+
+	if tokens < 1 {
+		remaining := time.Duration((1 - tokens) * float64(cd.Interval))
+		return RateLimit{}, &CooldownError{Remaining: remaining.Round(time.Millisecond)}
+	}
+	return RateLimit{UpdatedAt: now, Tokens: tokens - 1}, nil
+}
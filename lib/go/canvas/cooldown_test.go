@@ -0,0 +1,71 @@
+package canvas_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestCooldownFor(t *testing.T) {
+	c := canvas.Canvas{AdminID: "admin"}
+
+	if got := c.CooldownFor("user", nil); got.Interval != 35*time.Second || got.Burst != 1 {
+		t.Fatalf("default cooldown: got %+v", got)
+	}
+	if got := c.CooldownFor("admin", nil); got.Interval != 0 {
+		t.Fatalf("admin cooldown: got %+v, want none", got)
+	}
+
+	c.Cooldown = &canvas.CooldownPolicy{
+		Interval:      60,
+		Burst:         5,
+		RoleIntervals: map[string]int{"booster": 30, "moderator": 10},
+	}
+	if got := c.CooldownFor("user", []string{"other"}); got.Interval != time.Minute || got.Burst != 5 {
+		t.Fatalf("policy cooldown: got %+v", got)
+	}
+	if got := c.CooldownFor("user", []string{"booster", "moderator"}); got.Interval != 10*time.Second {
+		t.Fatalf("role cooldown: got %+v, want the shortest override", got)
+	}
+}
+
+func TestPlacePixelBurst(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	cooldown := canvas.Cooldown{Interval: 10 * time.Second, Burst: 3}
+	now := time.Now()
+
+	place := func(at time.Time) error {
+		return store.PlacePixel(ctx, "c1", canvas.Pixel{Color: "#000000", AuthorID: "user", UpdatedAt: at}, cooldown)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := place(now); err != nil {
+			t.Fatalf("placement %d of the burst failed: %v", i, err)
+		}
+	}
+
+	var cooldownErr *canvas.CooldownError
+	if err := place(now.Add(4 * time.Second)); !errors.As(err, &cooldownErr) || cooldownErr.Remaining != 6*time.Second {
+		t.Fatalf("got %v, want 6s cooldown", err)
+	}
+
+	// Waiting 25s earns back 2.5 tokens: two pixels, then a 5s wait.
+	if err := place(now.Add(25 * time.Second)); err != nil {
+		t.Fatalf("placement after refill failed: %v", err)
+	}
+	if err := place(now.Add(25 * time.Second)); err != nil {
+		t.Fatalf("second placement after refill failed: %v", err)
+	}
+	if err := place(now.Add(25 * time.Second)); !errors.As(err, &cooldownErr) || cooldownErr.Remaining != 5*time.Second {
+		t.Fatalf("got %v, want 5s cooldown", err)
+	}
+
+	// Buckets are per canvas.
+	if err := store.PlacePixel(ctx, "c2", canvas.Pixel{Color: "#000000", AuthorID: "user", UpdatedAt: now.Add(25 * time.Second)}, cooldown); err != nil {
+		t.Fatalf("placement on another canvas failed: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Pub/Sub delivers at least once, so consumers claim each message ID in
+// processed_messages/{consumer}_{messageID} before applying it. Claims first
+// hold for ProcessingLease, then for the dedup window once the message was
+// applied; a Firestore TTL policy on ExpiresAt deletes them afterwards.
+const ProcessedMessagesCollection = "processed_messages"
+
+// DefaultDedupWindow covers Pub/Sub redeliveries after a missed ack
+// deadline as well as the retries of failed events.
+const DefaultDedupWindow = time.Hour
+
+// ProcessingLease is how long a claim holds while the message is being
+// applied. It outlasts the longest function timeout, 9 minutes, so a
+// function killed mid-process lets the redeliveries after it run again.
+const ProcessingLease = 10 * time.Minute
+
+type ProcessedMessage struct {
+	Consumer    string    `firestore:"Consumer"`
+	MessageID   string    `firestore:"MessageID"`
+	ProcessedAt time.Time `firestore:"ProcessedAt"`
+	ExpiresAt   time.Time `firestore:"ExpiresAt"`
+}
+
+func ProcessedMessagePath(consumer, messageID string) string {
+	return fmt.Sprintf("%s/%s_%s", ProcessedMessagesCollection, consumer, messageID)
+}
+
+// ParseDedupWindow parses the DEDUP_WINDOW setting of the functions, a
+// time.ParseDuration string. Empty means DefaultDedupWindow.
+func ParseDedupWindow(s string) (time.Duration, error) {
+	if s == "" {
+		return DefaultDedupWindow, nil
+	}
+
+	window, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid dedup window %q: %w", s, err)
+	}
+	if window <= 0 {
+		return 0, fmt.Errorf("invalid dedup window %q, must be positive", s)
+	}
+	return window, nil
+}
+
+// ProcessOnce runs process for the first delivery of messageID to consumer
+// within window and reports whether it ran. The claim only holds for
+// ProcessingLease until process succeeds. When process fails or panics the
+// claim is released so the redelivery runs it again. Messages without an
+// ID, as in local runs, are always processed.
+func ProcessOnce(ctx context.Context, store Store, consumer, messageID string, window time.Duration, process func() error) (bool, error) {
+	if messageID == "" {
+		return true, process()
+	}
+
+	first, err := store.ClaimMessage(ctx, consumer, messageID, time.Now(), ProcessingLease)
+	if err != nil {
+		return false, err
+	}
+	if !first {
+		return false, nil
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = store.ReleaseMessage(ctx, consumer, messageID)
+			panic(r)
+		}
+	}()
+
+	if err := process(); err != nil {
+		return true, errors.Join(err, store.ReleaseMessage(ctx, consumer, messageID))
+	}
+	// The message was applied, failing to extend the claim only lets a
+	// redelivery coming after the lease apply it twice.
+	_ = store.CompleteMessage(ctx, consumer, messageID, time.Now(), window)
+	return true, nil
+}
@@ -0,0 +1,140 @@
+package canvas_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestProcessOnce(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	runs := 0
+	process := func() error {
+		runs++
+		return nil
+	}
+
+	for i := range 3 {
+		ran, err := canvas.ProcessOnce(ctx, store, "draw-pixel", "m1", time.Hour, process)
+		if err != nil {
+			t.Fatalf("delivery %d: %v", i, err)
+		}
+		if ran != (i == 0) {
+			t.Fatalf("delivery %d: ran = %v", i, ran)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("got %d runs, want 1", runs)
+	}
+
+	// Consumers deduplicate on their own.
+	if ran, _ := canvas.ProcessOnce(ctx, store, "snap", "m1", time.Hour, process); !ran {
+		t.Fatal("another consumer should process the message")
+	}
+
+	// Failures release the claim so the retry runs.
+	failure := errors.New("firestore unavailable")
+	if _, err := canvas.ProcessOnce(ctx, store, "draw-pixel", "m2", time.Hour, func() error { return failure }); !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if ran, _ := canvas.ProcessOnce(ctx, store, "draw-pixel", "m2", time.Hour, process); !ran {
+		t.Fatal("retry after a failure should run")
+	}
+
+	// Messages without an ID are never deduplicated.
+	for range 2 {
+		if ran, _ := canvas.ProcessOnce(ctx, store, "draw-pixel", "", time.Hour, process); !ran {
+			t.Fatal("message without ID should run")
+		}
+	}
+}
+
+func TestProcessOnceInterrupted(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	process := func() error { return nil }
+
+	// A panicking process releases its claim.
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the panic should be propagated")
+			}
+		}()
+		_, _ = canvas.ProcessOnce(ctx, store, "draw-pixel", "m1", time.Hour, func() error { panic("out of memory") })
+	}()
+	if ran, _ := canvas.ProcessOnce(ctx, store, "draw-pixel", "m1", time.Hour, process); !ran {
+		t.Fatal("redelivery after a panic should run")
+	}
+
+	// A process that never returns, as when the function times out, only
+	// holds its claim for the lease.
+	started := make(chan struct{})
+	go func() {
+		_, _ = canvas.ProcessOnce(ctx, store, "draw-pixel", "m2", time.Hour, func() error {
+			close(started)
+			select {}
+		})
+	}()
+	<-started
+	if ran, _ := canvas.ProcessOnce(ctx, store, "draw-pixel", "m2", time.Hour, process); ran {
+		t.Fatal("redelivery during the lease should be skipped")
+	}
+	if first, _ := store.ClaimMessage(ctx, "draw-pixel", "m2", time.Now().Add(canvas.ProcessingLease), time.Hour); !first {
+		t.Fatal("redelivery after the lease should run")
+	}
+
+	// Processed messages hold their claim for the whole window.
+	if first, _ := store.ClaimMessage(ctx, "draw-pixel", "m1", time.Now().Add(canvas.ProcessingLease), time.Hour); first {
+		t.Fatal("processed message should stay claimed for the window")
+	}
+
+	if _, err := store.ClaimMessage(ctx, "draw-pixel", "", time.Now(), time.Hour); err == nil {
+		t.Fatal("claiming an empty message ID should fail")
+	}
+}
+
+func TestMemoryStoreClaimMessageExpires(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if first, _ := store.ClaimMessage(ctx, "session-router", "m1", now, time.Minute); !first {
+		t.Fatal("first claim should succeed")
+	}
+	if first, _ := store.ClaimMessage(ctx, "session-router", "m1", now.Add(59*time.Second), time.Minute); first {
+		t.Fatal("claim inside the window should be a duplicate")
+	}
+	if first, _ := store.ClaimMessage(ctx, "session-router", "m1", now.Add(time.Minute), time.Minute); !first {
+		t.Fatal("claim after the window should succeed")
+	}
+}
+
+func TestParseDedupWindow(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "", want: canvas.DefaultDedupWindow},
+		{in: "10m", want: 10 * time.Minute},
+		{in: "24h", want: 24 * time.Hour},
+		{in: "0s", wantErr: true},
+		{in: "-1h", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := canvas.ParseDedupWindow(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ParseDedupWindow(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseDedupWindow(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
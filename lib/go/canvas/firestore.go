@@ -0,0 +1,701 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const statusField = "Status"
+
+type FirestoreStore struct {
+	client *firestore.Client
+}
+
+func NewFirestoreStore(ctx context.Context, projectID, database string) (*FirestoreStore, error) {
+	if projectID == "" {
+		return nil, errors.New("projectID missing")
+	}
+	if database == "" {
+		return nil, errors.New("database missing")
+	}
+
+	client, err := firestore.NewClientWithDatabase(ctx, projectID, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init Firestore: %w", err)
+	}
+	return &FirestoreStore{client: client}, nil
+}
+
+func (s *FirestoreStore) Close() error {
+	return s.client.Close()
+}
+
+func notFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *FirestoreStore) GetCanvas(ctx context.Context, canvasID string) (*Canvas, error) {
+	if canvasID == "" {
+		return nil, errors.New("canvasID missing")
+	}
+
+	doc, err := s.client.Doc(CanvasPath(canvasID)).Get(ctx)
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	var c Canvas
+	if err := doc.DataTo(&c); err != nil {
+		return nil, err
+	}
+	c.ID = doc.Ref.ID
+
+	return &c, nil
+}
+
+func (s *FirestoreStore) SaveCanvas(ctx context.Context, c *Canvas) error {
+	if c.ID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(c.ID)).Set(ctx, c)
+	return err
+}
+
+func (s *FirestoreStore) ListCanvases(ctx context.Context, st CanvasStatus) ([]Canvas, error) {
+	iter := s.client.Collection(CanvasesCollection).Where(statusField, "==", st).Documents(ctx)
+	defer iter.Stop()
+
+	var canvases []Canvas
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var c Canvas
+		if err := doc.DataTo(&c); err != nil {
+			return nil, fmt.Errorf("canvas %s: %w", doc.Ref.ID, err)
+		}
+		c.ID = doc.Ref.ID
+		canvases = append(canvases, c)
+	}
+
+	return canvases, nil
+}
+
+func (s *FirestoreStore) SetStatus(ctx context.Context, canvasID string, st CanvasStatus) error {
+	if canvasID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: statusField, Value: st},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) SetCooldownPolicy(ctx context.Context, canvasID string, policy CooldownPolicy) error {
+	if canvasID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: "Cooldown", Value: policy},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) SetPalette(ctx context.Context, canvasID string, palette []string) error {
+	if canvasID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: "Palette", Value: palette},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) SetFinalSnapshotAt(ctx context.Context, canvasID string, at time.Time) error {
+	if canvasID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: "FinalSnapshotAt", Value: at},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) SetRegions(ctx context.Context, canvasID string, regions []Region) error {
+	if canvasID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: "Regions", Value: regions},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) BanAuthor(ctx context.Context, canvasID string, authorID string) error {
+	if canvasID == "" {
+		return errors.New("canvasID missing")
+	}
+	if authorID == "" {
+		return errors.New("authorID missing")
+	}
+
+	_, err := s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: "Banned", Value: firestore.ArrayUnion(authorID)},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) StopCanvas(ctx context.Context, canvasID string, endedBy string, endedAt time.Time) (*Canvas, error) {
+	if canvasID == "" {
+		return nil, errors.New("canvasID missing")
+	}
+
+	ref := s.client.Doc(CanvasPath(canvasID))
+	var c Canvas
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil {
+			return notFound(err)
+		}
+		c = Canvas{}
+		if err := doc.DataTo(&c); err != nil {
+			return err
+		}
+		c.ID = doc.Ref.ID
+
+		if c.resumesStop(endedBy) {
+			return nil
+		}
+		if _, err := Transition(&c, ActionStop); err != nil {
+			return err
+		}
+
+		c.Status = StatusStop
+		c.EndedBy = endedBy
+		c.EndedAt = endedAt
+		return tx.Update(ref, []firestore.Update{
+			{Path: statusField, Value: c.Status},
+			{Path: "EndedBy", Value: c.EndedBy},
+			{Path: "EndedAt", Value: c.EndedAt},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// PlacePixel checks the status in its transaction, so no pixel lands
+	// after the flip and the count is final.
+	count, err := s.CountPixels(ctx, canvasID)
+	if err != nil {
+		return nil, err
+	}
+	c.PixelCount = count
+
+	_, err = ref.Update(ctx, []firestore.Update{
+		{Path: "PixelCount", Value: c.PixelCount},
+	})
+	if err != nil {
+		return nil, notFound(err)
+	}
+
+	return &c, nil
+}
+
+// layout returns how the pixels of canvasID are stored. Canvases not saved
+// yet use LayoutPixels.
+func (s *FirestoreStore) layout(ctx context.Context, canvasID string) (PixelLayout, error) {
+	c, err := s.GetCanvas(ctx, canvasID)
+	if errors.Is(err, ErrNotFound) {
+		return LayoutPixels, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return c.Layout, nil
+}
+
+func (s *FirestoreStore) PutPixel(ctx context.Context, canvasID string, pixel Pixel) error {
+	layout, err := s.layout(ctx, canvasID)
+	if err != nil {
+		return err
+	}
+	if layout == LayoutChunks {
+		return s.putChunkPixel(ctx, canvasID, pixel)
+	}
+
+	_, err = s.client.Doc(PixelPath(canvasID, pixel.X, pixel.Y)).Set(ctx, pixel)
+	return err
+}
+
+func (s *FirestoreStore) ListPixels(ctx context.Context, canvasID string) ([]Pixel, error) {
+	layout, err := s.layout(ctx, canvasID)
+	if err != nil {
+		return nil, err
+	}
+	if layout == LayoutChunks {
+		return s.listChunkPixels(ctx, canvasID)
+	}
+	return s.listPixelDocs(ctx, canvasID)
+}
+
+func (s *FirestoreStore) listPixelDocs(ctx context.Context, canvasID string) ([]Pixel, error) {
+	return s.queryPixels(ctx, s.client.Collection(PixelsPath(canvasID)).Query)
+}
+
+func (s *FirestoreStore) ListPixelsSince(ctx context.Context, canvasID string, since time.Time) ([]Pixel, error) {
+	layout, err := s.layout(ctx, canvasID)
+	if err != nil {
+		return nil, err
+	}
+	if layout == LayoutChunks {
+		return s.listChunkAreasSince(ctx, canvasID, since)
+	}
+	return s.queryPixels(ctx, s.client.Collection(PixelsPath(canvasID)).Where("UpdatedAt", ">", since))
+}
+
+func (s *FirestoreStore) queryPixels(ctx context.Context, query firestore.Query) ([]Pixel, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var pixels []Pixel
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var pixel Pixel
+		if err := doc.DataTo(&pixel); err != nil {
+			return nil, fmt.Errorf("pixel %s: %w", doc.Ref.ID, err)
+		}
+		pixels = append(pixels, pixel)
+	}
+
+	return pixels, nil
+}
+
+func (s *FirestoreStore) CountPixels(ctx context.Context, canvasID string) (int, error) {
+	layout, err := s.layout(ctx, canvasID)
+	if err != nil {
+		return 0, err
+	}
+	if layout == LayoutChunks {
+		pixels, err := s.listChunkPixels(ctx, canvasID)
+		return len(pixels), err
+	}
+
+	// Pixels drawn back to DefaultColor are not counted, chunks cannot
+	// tell them from pixels never drawn.
+	query := s.client.Collection(PixelsPath(canvasID)).Where("Color", "!=", DefaultColor)
+	res, err := query.NewAggregationQuery().WithCount("count").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count, ok := res["count"].(*firestorepb.Value)
+	if !ok {
+		return 0, errors.New("count missing from aggregation result")
+	}
+	return int(count.GetIntegerValue()), nil
+}
+
+// DeletePixels deletes both layouts, so canvases caught halfway through
+// MigrateToChunks are reset too.
+func (s *FirestoreStore) DeletePixels(ctx context.Context, canvasID string) error {
+	err := errors.Join(
+		s.deleteCollection(ctx, PixelsPath(canvasID)),
+		s.deleteCollection(ctx, ChunksPath(canvasID)),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.Doc(CanvasPath(canvasID)).Update(ctx, []firestore.Update{
+		{Path: "ResetAt", Value: time.Now()},
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *FirestoreStore) DeleteSession(ctx context.Context, canvasID string) error {
+	return errors.Join(
+		s.deleteCollection(ctx, HistoryPath(canvasID)),
+		s.deleteCollection(ctx, RateLimitsPath(canvasID)),
+	)
+}
+
+func (s *FirestoreStore) deleteCollection(ctx context.Context, path string) error {
+	bw := s.client.BulkWriter(ctx)
+
+	iter := s.client.Collection(path).DocumentRefs(ctx)
+
+	var jobs []*firestore.BulkWriterJob
+	for {
+		ref, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			bw.End()
+			return err
+		}
+
+		job, err := bw.Delete(ref)
+		if err != nil {
+			bw.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+
+	bw.End()
+
+	var errs []error
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// getAllBatch bounds the documents read by one GetAll call.
+const getAllBatch = 500
+
+func (s *FirestoreStore) PutPixels(ctx context.Context, canvasID string, pixels []Pixel) (int, error) {
+	layout, err := s.layout(ctx, canvasID)
+	if err != nil {
+		return 0, err
+	}
+	if layout == LayoutChunks {
+		return s.putChunkPixels(ctx, canvasID, pixels)
+	}
+
+	refs := make([]*firestore.DocumentRef, len(pixels))
+	for i, pixel := range pixels {
+		refs[i] = s.client.Doc(PixelPath(canvasID, pixel.X, pixel.Y))
+	}
+
+	previous := make([]string, 0, len(pixels))
+	for start := 0; start < len(refs); start += getAllBatch {
+		docs, err := s.client.GetAll(ctx, refs[start:min(start+getAllBatch, len(refs))])
+		if err != nil {
+			return 0, err
+		}
+		for _, doc := range docs {
+			color := DefaultColor
+			if doc.Exists() {
+				var pixel Pixel
+				if err := doc.DataTo(&pixel); err != nil {
+					return 0, fmt.Errorf("pixel %s: %w", doc.Ref.ID, err)
+				}
+				color = pixel.Color
+			}
+			previous = append(previous, color)
+		}
+	}
+
+	bw := s.client.BulkWriter(ctx)
+	history := s.client.Collection(HistoryPath(canvasID))
+
+	// Only pixels whose own write succeeded are counted as changed, a
+	// failed history write is reported but the pixel did change.
+	var pixelJobs, historyJobs []*firestore.BulkWriterJob
+	var errs []error
+	for i, pixel := range pixels {
+		if previous[i] == pixel.Color {
+			continue
+		}
+
+		placement := Placement{
+			CanvasID:      canvasID,
+			X:             pixel.X,
+			Y:             pixel.Y,
+			Color:         pixel.Color,
+			PreviousColor: previous[i],
+			AuthorID:      pixel.AuthorID,
+			PlacedAt:      pixel.UpdatedAt,
+		}
+
+		job, err := bw.Set(refs[i], pixel)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		pixelJobs = append(pixelJobs, job)
+
+		job, err = bw.Create(history.NewDoc(), placement)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		historyJobs = append(historyJobs, job)
+	}
+
+	bw.End()
+
+	changed := 0
+	for _, job := range pixelJobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed++
+	}
+	for _, job := range historyJobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return changed, errors.Join(errs...)
+}
+
+func (s *FirestoreStore) PlacePixel(ctx context.Context, canvasID string, pixel Pixel, cooldown Cooldown) error {
+	if pixel.AuthorID == "" {
+		return errors.New("authorID missing")
+	}
+
+	canvasRef := s.client.Doc(CanvasPath(canvasID))
+	pixelRef := s.client.Doc(PixelPath(canvasID, pixel.X, pixel.Y))
+	cx, cy := ChunkOf(pixel.X, pixel.Y)
+	chunkRef := s.client.Doc(ChunkPath(canvasID, cx, cy))
+	limitRef := s.client.Doc(RateLimitPath(canvasID, pixel.AuthorID))
+	historyRef := s.client.Collection(HistoryPath(canvasID)).NewDoc()
+
+	return s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var c Canvas
+		canvasDoc, err := tx.Get(canvasRef)
+		switch {
+		case err == nil:
+			if err := canvasDoc.DataTo(&c); err != nil {
+				return err
+			}
+			// The canvas may have been paused or ended since the caller
+			// checked it.
+			if c.Status != StatusStart || !c.OpenAt(pixel.UpdatedAt) {
+				return ErrNotRunning
+			}
+		case status.Code(err) != codes.NotFound:
+			return err
+		}
+
+		limitDoc, err := tx.Get(limitRef)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+
+		var last *RateLimit
+		if err == nil {
+			last = &RateLimit{}
+			if err := limitDoc.DataTo(last); err != nil {
+				return err
+			}
+		}
+		limit, err := cooldown.take(last, pixel.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		previous := Pixel{Color: DefaultColor}
+		var chunk *Chunk
+		if c.Layout == LayoutChunks {
+			chunkDoc, err := tx.Get(chunkRef)
+			if err != nil && status.Code(err) != codes.NotFound {
+				return err
+			}
+			if chunk, err = readChunk(chunkDoc, cx, cy); err != nil {
+				return err
+			}
+			previous.Color = chunk.Color(pixel.X, pixel.Y)
+		} else {
+			pixelDoc, err := tx.Get(pixelRef)
+			switch {
+			case err == nil:
+				if err := pixelDoc.DataTo(&previous); err != nil {
+					return err
+				}
+			case status.Code(err) != codes.NotFound:
+				return err
+			}
+		}
+
+		placement := Placement{
+			CanvasID:      canvasID,
+			X:             pixel.X,
+			Y:             pixel.Y,
+			Color:         pixel.Color,
+			PreviousColor: previous.Color,
+			AuthorID:      pixel.AuthorID,
+			PlacedAt:      pixel.UpdatedAt,
+		}
+
+		if chunk != nil {
+			if err := chunk.Set(pixel.X, pixel.Y, pixel.Color); err != nil {
+				return err
+			}
+			chunk.UpdatedAt = pixel.UpdatedAt
+			if err := tx.Set(chunkRef, chunk); err != nil {
+				return err
+			}
+		} else if err := tx.Set(pixelRef, pixel); err != nil {
+			return err
+		}
+		if err := tx.Create(historyRef, placement); err != nil {
+			return err
+		}
+		return tx.Set(limitRef, limit)
+	})
+}
+
+func (s *FirestoreStore) queryHistory(ctx context.Context, query firestore.Query) ([]Placement, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var placements []Placement
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var placement Placement
+		if err := doc.DataTo(&placement); err != nil {
+			return nil, fmt.Errorf("placement %s: %w", doc.Ref.ID, err)
+		}
+		placements = append(placements, placement)
+	}
+
+	// Sorting here instead of with OrderBy keeps the equality queries
+	// servable by single-field indexes.
+	sortPlacements(placements)
+	return placements, nil
+}
+
+func (s *FirestoreStore) CoordinateHistory(ctx context.Context, canvasID string, x, y int) ([]Placement, error) {
+	query := s.client.Collection(HistoryPath(canvasID)).
+		Where("X", "==", x).
+		Where("Y", "==", y)
+	return s.queryHistory(ctx, query)
+}
+
+func (s *FirestoreStore) AuthorHistory(ctx context.Context, canvasID string, authorID string) ([]Placement, error) {
+	query := s.client.Collection(HistoryPath(canvasID)).
+		Where("AuthorID", "==", authorID)
+	return s.queryHistory(ctx, query)
+}
+
+func (s *FirestoreStore) HistoryBetween(ctx context.Context, canvasID string, from, to time.Time) ([]Placement, error) {
+	query := s.client.Collection(HistoryPath(canvasID)).
+		Where("PlacedAt", ">=", from).
+		Where("PlacedAt", "<", to)
+	return s.queryHistory(ctx, query)
+}
+
+func (s *FirestoreStore) LastPixelTime(ctx context.Context, canvasID string, authorID string) (time.Time, error) {
+	if authorID == "" {
+		return time.Time{}, errors.New("authorID missing")
+	}
+
+	doc, err := s.client.Doc(RateLimitPath(canvasID, authorID)).Get(ctx)
+	if err != nil {
+		return time.Time{}, notFound(err)
+	}
+
+	var limit RateLimit
+	if err := doc.DataTo(&limit); err != nil {
+		return time.Time{}, err
+	}
+	if limit.UpdatedAt.IsZero() {
+		return time.Time{}, ErrNotFound
+	}
+
+	return limit.UpdatedAt, nil
+}
+
+func (s *FirestoreStore) SetLastPixelTime(ctx context.Context, canvasID string, authorID string, t time.Time) error {
+	if authorID == "" {
+		return errors.New("authorID missing")
+	}
+
+	_, err := s.client.Doc(RateLimitPath(canvasID, authorID)).Set(ctx, RateLimit{UpdatedAt: t})
+	return err
+}
+
+func (s *FirestoreStore) ClaimMessage(ctx context.Context, consumer, messageID string, now time.Time, window time.Duration) (bool, error) {
+	if messageID == "" {
+		return false, errors.New("messageID missing")
+	}
+
+	ref := s.client.Doc(ProcessedMessagePath(consumer, messageID))
+
+	var first bool
+	err := s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		switch {
+		case err == nil:
+			var claim ProcessedMessage
+			if err := doc.DataTo(&claim); err != nil {
+				return err
+			}
+			// The TTL policy deletes expired claims lazily.
+			if claim.ExpiresAt.After(now) {
+				first = false
+				return nil
+			}
+		case status.Code(err) != codes.NotFound:
+			return err
+		}
+
+		first = true
+		return tx.Set(ref, ProcessedMessage{
+			Consumer:    consumer,
+			MessageID:   messageID,
+			ProcessedAt: now,
+			ExpiresAt:   now.Add(window),
+		})
+	})
+	return first, err
+}
+
+func (s *FirestoreStore) CompleteMessage(ctx context.Context, consumer, messageID string, now time.Time, window time.Duration) error {
+	_, err := s.client.Doc(ProcessedMessagePath(consumer, messageID)).Update(ctx, []firestore.Update{
+		{Path: "ProcessedAt", Value: now},
+		{Path: "ExpiresAt", Value: now.Add(window)},
+	})
+	return notFound(err)
+}
+
+func (s *FirestoreStore) ReleaseMessage(ctx context.Context, consumer, messageID string) error {
+	_, err := s.client.Doc(ProcessedMessagePath(consumer, messageID)).Delete(ctx)
+	return err
+}
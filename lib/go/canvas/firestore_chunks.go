@@ -0,0 +1,278 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// readChunk decodes the chunk (cx, cy) from doc, an empty one when the
+// document does not exist.
+func readChunk(doc *firestore.DocumentSnapshot, cx, cy int) (*Chunk, error) {
+	if doc == nil || !doc.Exists() {
+		return NewChunk(cx, cy), nil
+	}
+
+	var ch Chunk
+	if err := doc.DataTo(&ch); err != nil {
+		return nil, fmt.Errorf("chunk %s: %w", doc.Ref.ID, err)
+	}
+	if err := ch.validate(); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (s *FirestoreStore) putChunkPixel(ctx context.Context, canvasID string, pixel Pixel) error {
+	cx, cy := ChunkOf(pixel.X, pixel.Y)
+	ref := s.client.Doc(ChunkPath(canvasID, cx, cy))
+
+	return s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		ch, err := readChunk(doc, cx, cy)
+		if err != nil {
+			return err
+		}
+
+		if err := ch.Set(pixel.X, pixel.Y, pixel.Color); err != nil {
+			return err
+		}
+		ch.UpdatedAt = pixel.UpdatedAt
+		return tx.Set(ref, ch)
+	})
+}
+
+func (s *FirestoreStore) listChunkPixels(ctx context.Context, canvasID string) ([]Pixel, error) {
+	return s.queryChunks(ctx, s.client.Collection(ChunksPath(canvasID)).Query, (*Chunk).Pixels)
+}
+
+func (s *FirestoreStore) listChunkAreasSince(ctx context.Context, canvasID string, since time.Time) ([]Pixel, error) {
+	query := s.client.Collection(ChunksPath(canvasID)).Where("UpdatedAt", ">", since)
+	return s.queryChunks(ctx, query, (*Chunk).Area)
+}
+
+// queryChunks returns the pixels picked by pixelsOf from each chunk query
+// returns.
+func (s *FirestoreStore) queryChunks(ctx context.Context, query firestore.Query, pixelsOf func(*Chunk) []Pixel) ([]Pixel, error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	var pixels []Pixel
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		ch, err := readChunk(doc, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		pixels = append(pixels, pixelsOf(ch)...)
+	}
+
+	return pixels, nil
+}
+
+// putChunkPixels reads every chunk the pixels fall in once, so a bulk write
+// costs one read and one write per chunk instead of per pixel.
+func (s *FirestoreStore) putChunkPixels(ctx context.Context, canvasID string, pixels []Pixel) (int, error) {
+	chunks := make(map[Point]*Chunk)
+	var coords []Point
+	var refs []*firestore.DocumentRef
+	for _, pixel := range pixels {
+		cx, cy := ChunkOf(pixel.X, pixel.Y)
+		point := Point{X: cx, Y: cy}
+		if _, ok := chunks[point]; ok {
+			continue
+		}
+		chunks[point] = nil
+		coords = append(coords, point)
+		refs = append(refs, s.client.Doc(ChunkPath(canvasID, cx, cy)))
+	}
+
+	for start := 0; start < len(refs); start += getAllBatch {
+		docs, err := s.client.GetAll(ctx, refs[start:min(start+getAllBatch, len(refs))])
+		if err != nil {
+			return 0, err
+		}
+		for i, doc := range docs {
+			point := coords[start+i]
+			ch, err := readChunk(doc, point.X, point.Y)
+			if err != nil {
+				return 0, err
+			}
+			chunks[point] = ch
+		}
+	}
+
+	// Pixels changed in each chunk, counted once the chunk is written.
+	changed := make(map[Point]int)
+	var placements []Placement
+	for _, pixel := range pixels {
+		cx, cy := ChunkOf(pixel.X, pixel.Y)
+		point := Point{X: cx, Y: cy}
+		ch := chunks[point]
+
+		previous := ch.Color(pixel.X, pixel.Y)
+		if previous == pixel.Color {
+			continue
+		}
+		if err := ch.Set(pixel.X, pixel.Y, pixel.Color); err != nil {
+			return 0, err
+		}
+		if pixel.UpdatedAt.After(ch.UpdatedAt) {
+			ch.UpdatedAt = pixel.UpdatedAt
+		}
+		changed[point]++
+
+		placements = append(placements, Placement{
+			CanvasID:      canvasID,
+			X:             pixel.X,
+			Y:             pixel.Y,
+			Color:         pixel.Color,
+			PreviousColor: previous,
+			AuthorID:      pixel.AuthorID,
+			PlacedAt:      pixel.UpdatedAt,
+		})
+	}
+
+	bw := s.client.BulkWriter(ctx)
+	history := s.client.Collection(HistoryPath(canvasID))
+
+	chunkJobs := make(map[Point]*firestore.BulkWriterJob)
+	var historyJobs []*firestore.BulkWriterJob
+	var errs []error
+	for i, point := range coords {
+		if changed[point] == 0 {
+			continue
+		}
+		job, err := bw.Set(refs[i], chunks[point])
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		chunkJobs[point] = job
+	}
+	for _, placement := range placements {
+		if len(errs) > 0 {
+			break
+		}
+		job, err := bw.Create(history.NewDoc(), placement)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		historyJobs = append(historyJobs, job)
+	}
+
+	bw.End()
+
+	count := 0
+	for point, job := range chunkJobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		count += changed[point]
+	}
+	for _, job := range historyJobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return count, errors.Join(errs...)
+}
+
+// MigrateToChunks moves the pixels of a canvas stored with LayoutPixels into
+// chunks, switches it to LayoutChunks and deletes the pixel documents. It
+// returns the number of pixels moved. Only paused or stopped canvases are
+// migrated, and the layout is not switched if the status changed while the
+// pixels were copied, so no placement is lost. Migrating a canvas twice is a
+// no-op.
+func (s *FirestoreStore) MigrateToChunks(ctx context.Context, canvasID string) (int, error) {
+	canvasRef := s.client.Doc(CanvasPath(canvasID))
+	doc, err := canvasRef.Get(ctx)
+	if err != nil {
+		return 0, notFound(err)
+	}
+	var c Canvas
+	if err := doc.DataTo(&c); err != nil {
+		return 0, err
+	}
+	if c.Layout == LayoutChunks {
+		return 0, nil
+	}
+	if c.Status != StatusPause && c.Status != StatusStop {
+		return 0, fmt.Errorf("canvas is %s, pause it before migrating", c.Status)
+	}
+
+	pixels, err := s.listPixelDocs(ctx, canvasID)
+	if err != nil {
+		return 0, err
+	}
+	chunks, err := ChunkPixels(pixels)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := s.client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	for _, ch := range chunks {
+		job, err := bw.Set(s.client.Doc(ChunkPath(canvasID, ch.CX, ch.CY)), ch)
+		if err != nil {
+			bw.End()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	bw.End()
+
+	var errs []error
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return 0, err
+	}
+
+	// The pixel documents stay the source of truth until the layout flips,
+	// so a failed migration can simply be run again. The flip is refused if
+	// the canvas changed since it was read: resumed meanwhile, it may have
+	// had pixels placed in the old layout, even if paused again since.
+	copiedAt := doc.UpdateTime
+	err = s.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(canvasRef)
+		if err != nil {
+			return notFound(err)
+		}
+		if !doc.UpdateTime.Equal(copiedAt) {
+			return errors.New("canvas changed while migrating, run it again")
+		}
+		return tx.Update(canvasRef, []firestore.Update{
+			{Path: "Layout", Value: LayoutChunks},
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.deleteCollection(ctx, PixelsPath(canvasID)); err != nil {
+		return len(pixels), fmt.Errorf("canvas migrated, deleting pixel documents: %w", err)
+	}
+	return len(pixels), nil
+}
@@ -0,0 +1,31 @@
+package canvas
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const HistoryCollection = "history"
+
+// Placement is one accepted pixel placement. Placements are only ever
+// appended to canvases/{canvasID}/history, never updated.
+type Placement struct {
+	CanvasID      string    `firestore:"CanvasID" json:"canvas_id"`
+	X             int       `firestore:"X" json:"x"`
+	Y             int       `firestore:"Y" json:"y"`
+	Color         string    `firestore:"Color" json:"color"`
+	PreviousColor string    `firestore:"PreviousColor" json:"previous_color"`
+	AuthorID      string    `firestore:"AuthorID" json:"author_id"`
+	PlacedAt      time.Time `firestore:"PlacedAt" json:"placed_at"`
+}
+
+func HistoryPath(canvasID string) string {
+	return fmt.Sprintf("%s/%s", CanvasPath(canvasID), HistoryCollection)
+}
+
+func sortPlacements(placements []Placement) {
+	sort.SliceStable(placements, func(i, j int) bool {
+		return placements[i].PlacedAt.Before(placements[j].PlacedAt)
+	})
+}
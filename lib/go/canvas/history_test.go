@@ -0,0 +1,52 @@
+package canvas_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestMemoryStoreHistory(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	place := func(author, color string, x, y int, at time.Duration) {
+		t.Helper()
+		pixel := canvas.Pixel{X: x, Y: y, Color: color, AuthorID: author, UpdatedAt: start.Add(at)}
+		if err := store.PlacePixel(ctx, "c1", pixel, canvas.Cooldown{}); err != nil {
+			t.Fatalf("PlacePixel failed: %v", err)
+		}
+	}
+
+	place("alice", "#FF0000", 1, 1, 0)
+	place("bob", "#00FF00", 1, 1, time.Minute)
+	place("alice", "#0000FF", 2, 2, 2*time.Minute)
+	place("alice", "#000000", 1, 1, 3*time.Minute)
+
+	coord, err := store.CoordinateHistory(ctx, "c1", 1, 1)
+	if err != nil {
+		t.Fatalf("CoordinateHistory failed: %v", err)
+	}
+	wantPrev := []string{canvas.DefaultColor, "#FF0000", "#00FF00"}
+	if len(coord) != len(wantPrev) {
+		t.Fatalf("got %d placements at 1,1, want %d", len(coord), len(wantPrev))
+	}
+	for i, p := range coord {
+		if p.PreviousColor != wantPrev[i] {
+			t.Errorf("placement %d: previous color %s, want %s", i, p.PreviousColor, wantPrev[i])
+		}
+	}
+
+	byAlice, _ := store.AuthorHistory(ctx, "c1", "alice")
+	if len(byAlice) != 3 {
+		t.Fatalf("got %d placements by alice, want 3", len(byAlice))
+	}
+
+	between, _ := store.HistoryBetween(ctx, "c1", start.Add(time.Minute), start.Add(3*time.Minute))
+	if len(between) != 2 || between[0].AuthorID != "bob" || between[1].Color != "#0000FF" {
+		t.Fatalf("unexpected placements in range: %+v", between)
+	}
+}
@@ -0,0 +1,125 @@
+package canvas
+
+import "fmt"
+
+// Action is a session lifecycle event, carried in the `action` attribute of
+// the session-events messages.
+type Action string
+
+const (
+	ActionStart  Action = "start"
+	ActionResume Action = "resume"
+	ActionPause  Action = "pause"
+	ActionStop   Action = "stop"
+	ActionReset  Action = "reset"
+	// ActionConfigure changes canvas settings and keeps its status.
+	ActionConfigure Action = "configure"
+	// ActionEdit covers the admin drawing tools, which write pixels in bulk
+	// and keep the canvas status.
+	ActionEdit Action = "edit"
+	// ActionOpen is sent by the scheduler when a scheduled canvas reaches
+	// its StartDate.
+	ActionOpen Action = "open"
+)
+
+// statusNone stands for a canvas that does not exist yet.
+const statusNone CanvasStatus = ""
+
+// transitions lists, for each action, the statuses it is allowed from and
+// the status the canvas ends up in.
+var transitions = map[Action]map[CanvasStatus]CanvasStatus{
+	// A stopped canvas can host a new session in the same channel.
+	ActionStart: {
+		statusNone: StatusStart,
+		StatusStop: StatusStart,
+	},
+	ActionResume: {
+		StatusPause: StatusStart,
+	},
+	ActionOpen: {
+		StatusScheduled: StatusStart,
+	},
+	ActionPause: {
+		StatusStart: StatusPause,
+	},
+	ActionStop: {
+		StatusScheduled: StatusStop,
+		StatusStart:     StatusStop,
+		StatusPause:     StatusStop,
+	},
+	ActionReset: {
+		StatusStart: StatusStart,
+		StatusPause: StatusPause,
+	},
+	ActionConfigure: {
+		StatusScheduled: StatusScheduled,
+		StatusStart:     StatusStart,
+		StatusPause:     StatusPause,
+	},
+	ActionEdit: {
+		StatusScheduled: StatusScheduled,
+		StatusStart:     StatusStart,
+		StatusPause:     StatusPause,
+	},
+}
+
+type TransitionError struct {
+	Action Action
+	From   CanvasStatus
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("canvas: cannot %s: %s", e.Action, e.Reason)
+}
+
+// resumesStop reports whether the canvas was stopped by endedBy without its
+// final snapshot being requested, by a stop that failed halfway.
+func (c *Canvas) resumesStop(endedBy string) bool {
+	return c.Status == StatusStop && c.EndedBy == endedBy && c.FinalSnapshotAt.IsZero()
+}
+
+// Transition validates action against the current canvas, nil when it does
+// not exist, and returns the status the canvas moves to.
+func Transition(c *Canvas, action Action) (CanvasStatus, error) {
+	from := statusNone
+	if c != nil {
+		from = c.Status
+	}
+
+	allowed, ok := transitions[action]
+	if !ok {
+		return from, &TransitionError{Action: action, From: from, Reason: "unknown action"}
+	}
+
+	to, ok := allowed[from]
+	if !ok {
+		return from, &TransitionError{Action: action, From: from, Reason: rejectReason(action, from)}
+	}
+	return to, nil
+}
+
+func rejectReason(action Action, from CanvasStatus) string {
+	switch from {
+	case statusNone:
+		return "canvas does not exist"
+	case StatusStart:
+		if action == ActionStart {
+			return "canvas is already running, stop it first"
+		}
+		return "canvas is running"
+	case StatusScheduled:
+		if action == ActionStart {
+			return "canvas is already scheduled, stop it first"
+		}
+		return "canvas is scheduled"
+	case StatusPause:
+		if action == ActionStart {
+			return "canvas is paused, use resume"
+		}
+		return "canvas is paused"
+	case StatusStop:
+		return "canvas is stopped"
+	}
+	return fmt.Sprintf("canvas has unknown status %q", from)
+}
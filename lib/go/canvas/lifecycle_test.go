@@ -0,0 +1,84 @@
+package canvas_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestTransition(t *testing.T) {
+	withStatus := func(status canvas.CanvasStatus) *canvas.Canvas {
+		return &canvas.Canvas{ID: "c1", Status: status}
+	}
+
+	tests := []struct {
+		name   string
+		canvas *canvas.Canvas
+		action canvas.Action
+		want   canvas.CanvasStatus
+		ok     bool
+	}{
+		{"start new canvas", nil, canvas.ActionStart, canvas.StatusStart, true},
+		{"start stopped canvas", withStatus(canvas.StatusStop), canvas.ActionStart, canvas.StatusStart, true},
+		{"start running canvas", withStatus(canvas.StatusStart), canvas.ActionStart, "", false},
+		{"start paused canvas", withStatus(canvas.StatusPause), canvas.ActionStart, "", false},
+		{"resume paused canvas", withStatus(canvas.StatusPause), canvas.ActionResume, canvas.StatusStart, true},
+		{"resume running canvas", withStatus(canvas.StatusStart), canvas.ActionResume, "", false},
+		{"resume stopped canvas", withStatus(canvas.StatusStop), canvas.ActionResume, "", false},
+		{"resume missing canvas", nil, canvas.ActionResume, "", false},
+		{"pause running canvas", withStatus(canvas.StatusStart), canvas.ActionPause, canvas.StatusPause, true},
+		{"pause stopped canvas", withStatus(canvas.StatusStop), canvas.ActionPause, "", false},
+		{"stop paused canvas", withStatus(canvas.StatusPause), canvas.ActionStop, canvas.StatusStop, true},
+		{"stop stopped canvas", withStatus(canvas.StatusStop), canvas.ActionStop, "", false},
+		{"reset stopped canvas", withStatus(canvas.StatusStop), canvas.ActionReset, "", false},
+		{"open scheduled canvas", withStatus(canvas.StatusScheduled), canvas.ActionOpen, canvas.StatusStart, true},
+		{"open running canvas", withStatus(canvas.StatusStart), canvas.ActionOpen, "", false},
+		{"start scheduled canvas", withStatus(canvas.StatusScheduled), canvas.ActionStart, "", false},
+		{"configure paused canvas", withStatus(canvas.StatusPause), canvas.ActionConfigure, canvas.StatusPause, true},
+		{"configure stopped canvas", withStatus(canvas.StatusStop), canvas.ActionConfigure, "", false},
+		{"edit scheduled canvas", withStatus(canvas.StatusScheduled), canvas.ActionEdit, canvas.StatusScheduled, true},
+		{"edit stopped canvas", withStatus(canvas.StatusStop), canvas.ActionEdit, "", false},
+		{"unknown action", withStatus(canvas.StatusStart), canvas.Action("explode"), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canvas.Transition(tt.canvas, tt.action)
+			if !tt.ok {
+				var transitionErr *canvas.TransitionError
+				if !errors.As(err, &transitionErr) {
+					t.Fatalf("got %v, want TransitionError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanvasOpenAt(t *testing.T) {
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	c := canvas.Canvas{StartDate: start, EndDate: start.Add(time.Hour)}
+
+	if c.OpenAt(start.Add(-time.Second)) {
+		t.Fatal("canvas open before its StartDate")
+	}
+	if !c.OpenAt(start) || !c.OpenAt(start.Add(59*time.Minute)) {
+		t.Fatal("canvas closed inside its window")
+	}
+	if c.OpenAt(start.Add(time.Hour)) {
+		t.Fatal("canvas open at its EndDate")
+	}
+
+	c.EndDate = time.Time{}
+	if !c.OpenAt(start.Add(24 * 365 * time.Hour)) {
+		t.Fatal("canvas without EndDate closed")
+	}
+}
@@ -0,0 +1,486 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MemoryStore struct {
+	mu         sync.Mutex
+	canvases   map[string]Canvas
+	pixels     map[string]map[string]Pixel
+	chunks     map[string]map[Point]*Chunk
+	history    map[string][]Placement
+	rateLimits map[string]RateLimit
+	processed  map[string]ProcessedMessage
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		canvases:   make(map[string]Canvas),
+		pixels:     make(map[string]map[string]Pixel),
+		chunks:     make(map[string]map[Point]*Chunk),
+		history:    make(map[string][]Placement),
+		rateLimits: make(map[string]RateLimit),
+		processed:  make(map[string]ProcessedMessage),
+	}
+}
+
+func (s *MemoryStore) GetCanvas(ctx context.Context, canvasID string) (*Canvas, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (s *MemoryStore) SaveCanvas(ctx context.Context, c *Canvas) error {
+	if c.ID == "" {
+		return errors.New("canvasID missing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.canvases[c.ID] = *c
+	return nil
+}
+
+func (s *MemoryStore) ListCanvases(ctx context.Context, status CanvasStatus) ([]Canvas, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var canvases []Canvas
+	for _, c := range s.canvases {
+		if c.Status == status {
+			canvases = append(canvases, c)
+		}
+	}
+	return canvases, nil
+}
+
+func (s *MemoryStore) SetStatus(ctx context.Context, canvasID string, status CanvasStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.Status = status
+	s.canvases[canvasID] = c
+	return nil
+}
+
+func (s *MemoryStore) SetCooldownPolicy(ctx context.Context, canvasID string, policy CooldownPolicy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.Cooldown = &policy
+	s.canvases[canvasID] = c
+	return nil
+}
+
+func (s *MemoryStore) SetPalette(ctx context.Context, canvasID string, palette []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.Palette = palette
+	s.canvases[canvasID] = c
+	return nil
+}
+
+func (s *MemoryStore) SetFinalSnapshotAt(ctx context.Context, canvasID string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.FinalSnapshotAt = at
+	s.canvases[canvasID] = c
+	return nil
+}
+
+func (s *MemoryStore) SetRegions(ctx context.Context, canvasID string, regions []Region) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return ErrNotFound
+	}
+	c.Regions = regions
+	s.canvases[canvasID] = c
+	return nil
+}
+
+func (s *MemoryStore) BanAuthor(ctx context.Context, canvasID string, authorID string) error {
+	if authorID == "" {
+		return errors.New("authorID missing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !slices.Contains(c.Banned, authorID) {
+		c.Banned = append(slices.Clone(c.Banned), authorID)
+	}
+	s.canvases[canvasID] = c
+	return nil
+}
+
+func (s *MemoryStore) StopCanvas(ctx context.Context, canvasID string, endedBy string, endedAt time.Time) (*Canvas, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.canvases[canvasID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !c.resumesStop(endedBy) {
+		if _, err := Transition(&c, ActionStop); err != nil {
+			return nil, err
+		}
+		c.Status = StatusStop
+		c.EndedBy = endedBy
+		c.EndedAt = endedAt
+	}
+	c.PixelCount = s.countPixels(canvasID)
+	s.canvases[canvasID] = c
+	return &c, nil
+}
+
+// chunked reports whether the pixels of canvasID are kept in chunks, as
+// FirestoreStore does for LayoutChunks canvases.
+func (s *MemoryStore) chunked(canvasID string) bool {
+	c, ok := s.canvases[canvasID]
+	return ok && c.Layout == LayoutChunks
+}
+
+// colorAt returns the stored color of (x, y), DefaultColor when never drawn.
+func (s *MemoryStore) colorAt(canvasID string, x, y int) string {
+	if s.chunked(canvasID) {
+		cx, cy := ChunkOf(x, y)
+		if ch, ok := s.chunks[canvasID][Point{X: cx, Y: cy}]; ok {
+			return ch.Color(x, y)
+		}
+		return DefaultColor
+	}
+	if pixel, ok := s.pixels[canvasID][PixelDocID(x, y)]; ok {
+		return pixel.Color
+	}
+	return DefaultColor
+}
+
+// put stores pixel in the layout of canvasID.
+func (s *MemoryStore) put(canvasID string, pixel Pixel) error {
+	if s.chunked(canvasID) {
+		chunks, ok := s.chunks[canvasID]
+		if !ok {
+			chunks = make(map[Point]*Chunk)
+			s.chunks[canvasID] = chunks
+		}
+		cx, cy := ChunkOf(pixel.X, pixel.Y)
+		ch, ok := chunks[Point{X: cx, Y: cy}]
+		if !ok {
+			ch = NewChunk(cx, cy)
+			chunks[Point{X: cx, Y: cy}] = ch
+		}
+		if err := ch.Set(pixel.X, pixel.Y, pixel.Color); err != nil {
+			return err
+		}
+		if pixel.UpdatedAt.After(ch.UpdatedAt) {
+			ch.UpdatedAt = pixel.UpdatedAt
+		}
+		return nil
+	}
+
+	pixels, ok := s.pixels[canvasID]
+	if !ok {
+		pixels = make(map[string]Pixel)
+		s.pixels[canvasID] = pixels
+	}
+	pixels[PixelDocID(pixel.X, pixel.Y)] = pixel
+	return nil
+}
+
+func (s *MemoryStore) PutPixel(ctx context.Context, canvasID string, pixel Pixel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.put(canvasID, pixel)
+}
+
+func (s *MemoryStore) ListPixels(ctx context.Context, canvasID string) ([]Pixel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.chunked(canvasID) {
+		var pixels []Pixel
+		for _, ch := range s.chunks[canvasID] {
+			pixels = append(pixels, ch.Pixels()...)
+		}
+		return pixels, nil
+	}
+
+	pixels := make([]Pixel, 0, len(s.pixels[canvasID]))
+	for _, pixel := range s.pixels[canvasID] {
+		pixels = append(pixels, pixel)
+	}
+	return pixels, nil
+}
+
+func (s *MemoryStore) ListPixelsSince(ctx context.Context, canvasID string, since time.Time) ([]Pixel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pixels []Pixel
+	if s.chunked(canvasID) {
+		for _, ch := range s.chunks[canvasID] {
+			if ch.UpdatedAt.After(since) {
+				pixels = append(pixels, ch.Area()...)
+			}
+		}
+		return pixels, nil
+	}
+
+	for _, pixel := range s.pixels[canvasID] {
+		if pixel.UpdatedAt.After(since) {
+			pixels = append(pixels, pixel)
+		}
+	}
+	return pixels, nil
+}
+
+func (s *MemoryStore) CountPixels(ctx context.Context, canvasID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.countPixels(canvasID), nil
+}
+
+func (s *MemoryStore) countPixels(canvasID string) int {
+	count := 0
+	if s.chunked(canvasID) {
+		for _, ch := range s.chunks[canvasID] {
+			count += len(ch.Pixels())
+		}
+		return count
+	}
+
+	for _, pixel := range s.pixels[canvasID] {
+		if pixel.Color != DefaultColor {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *MemoryStore) DeletePixels(ctx context.Context, canvasID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.pixels, canvasID)
+	delete(s.chunks, canvasID)
+	if c, ok := s.canvases[canvasID]; ok {
+		c.ResetAt = time.Now()
+		s.canvases[canvasID] = c
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteSession(ctx context.Context, canvasID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.history, canvasID)
+	for path := range s.rateLimits {
+		if strings.HasPrefix(path, RateLimitsPath(canvasID)+"/") {
+			delete(s.rateLimits, path)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) PutPixels(ctx context.Context, canvasID string, pixels []Pixel) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := 0
+	for _, pixel := range pixels {
+		previous := s.colorAt(canvasID, pixel.X, pixel.Y)
+		if previous == pixel.Color {
+			continue
+		}
+
+		if err := s.put(canvasID, pixel); err != nil {
+			return changed, err
+		}
+		s.history[canvasID] = append(s.history[canvasID], Placement{
+			CanvasID:      canvasID,
+			X:             pixel.X,
+			Y:             pixel.Y,
+			Color:         pixel.Color,
+			PreviousColor: previous,
+			AuthorID:      pixel.AuthorID,
+			PlacedAt:      pixel.UpdatedAt,
+		})
+		changed++
+	}
+	return changed, nil
+}
+
+func (s *MemoryStore) PlacePixel(ctx context.Context, canvasID string, pixel Pixel, cooldown Cooldown) error {
+	if pixel.AuthorID == "" {
+		return errors.New("authorID missing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if c, ok := s.canvases[canvasID]; ok && (c.Status != StatusStart || !c.OpenAt(pixel.UpdatedAt)) {
+		return ErrNotRunning
+	}
+
+	var last *RateLimit
+	if limit, ok := s.rateLimits[RateLimitPath(canvasID, pixel.AuthorID)]; ok {
+		last = &limit
+	}
+	limit, err := cooldown.take(last, pixel.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	previous := s.colorAt(canvasID, pixel.X, pixel.Y)
+	if err := s.put(canvasID, pixel); err != nil {
+		return err
+	}
+	s.history[canvasID] = append(s.history[canvasID], Placement{
+		CanvasID:      canvasID,
+		X:             pixel.X,
+		Y:             pixel.Y,
+		Color:         pixel.Color,
+		PreviousColor: previous,
+		AuthorID:      pixel.AuthorID,
+		PlacedAt:      pixel.UpdatedAt,
+	})
+	s.rateLimits[RateLimitPath(canvasID, pixel.AuthorID)] = limit
+	return nil
+}
+
+func (s *MemoryStore) filterHistory(canvasID string, keep func(Placement) bool) []Placement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var placements []Placement
+	for _, placement := range s.history[canvasID] {
+		if keep(placement) {
+			placements = append(placements, placement)
+		}
+	}
+	sortPlacements(placements)
+	return placements
+}
+
+func (s *MemoryStore) CoordinateHistory(ctx context.Context, canvasID string, x, y int) ([]Placement, error) {
+	return s.filterHistory(canvasID, func(p Placement) bool {
+		return p.X == x && p.Y == y
+	}), nil
+}
+
+func (s *MemoryStore) AuthorHistory(ctx context.Context, canvasID string, authorID string) ([]Placement, error) {
+	return s.filterHistory(canvasID, func(p Placement) bool {
+		return p.AuthorID == authorID
+	}), nil
+}
+
+func (s *MemoryStore) HistoryBetween(ctx context.Context, canvasID string, from, to time.Time) ([]Placement, error) {
+	return s.filterHistory(canvasID, func(p Placement) bool {
+		return !p.PlacedAt.Before(from) && p.PlacedAt.Before(to)
+	}), nil
+}
+
+func (s *MemoryStore) LastPixelTime(ctx context.Context, canvasID string, authorID string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limit, ok := s.rateLimits[RateLimitPath(canvasID, authorID)]
+	if !ok {
+		return time.Time{}, ErrNotFound
+	}
+	return limit.UpdatedAt, nil
+}
+
+func (s *MemoryStore) SetLastPixelTime(ctx context.Context, canvasID string, authorID string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rateLimits[RateLimitPath(canvasID, authorID)] = RateLimit{UpdatedAt: t}
+	return nil
+}
+
+func (s *MemoryStore) ClaimMessage(ctx context.Context, consumer, messageID string, now time.Time, window time.Duration) (bool, error) {
+	if messageID == "" {
+		return false, errors.New("messageID missing")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := ProcessedMessagePath(consumer, messageID)
+	if claim, ok := s.processed[path]; ok && claim.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	s.processed[path] = ProcessedMessage{
+		Consumer:    consumer,
+		MessageID:   messageID,
+		ProcessedAt: now,
+		ExpiresAt:   now.Add(window),
+	}
+	return true, nil
+}
+
+func (s *MemoryStore) CompleteMessage(ctx context.Context, consumer, messageID string, now time.Time, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := ProcessedMessagePath(consumer, messageID)
+	claim, ok := s.processed[path]
+	if !ok {
+		return ErrNotFound
+	}
+	claim.ProcessedAt = now
+	claim.ExpiresAt = now.Add(window)
+	s.processed[path] = claim
+	return nil
+}
+
+func (s *MemoryStore) ReleaseMessage(ctx context.Context, consumer, messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.processed, ProcessedMessagePath(consumer, messageID))
+	return nil
+}
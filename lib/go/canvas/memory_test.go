@@ -0,0 +1,331 @@
+package canvas_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestMemoryStoreCanvas(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	if _, err := store.GetCanvas(ctx, "missing"); !errors.Is(err, canvas.ErrNotFound) {
+		t.Fatalf("GetCanvas on missing canvas: got %v, want ErrNotFound", err)
+	}
+	if err := store.SetStatus(ctx, "missing", canvas.StatusPause); !errors.Is(err, canvas.ErrNotFound) {
+		t.Fatalf("SetStatus on missing canvas: got %v, want ErrNotFound", err)
+	}
+
+	c := &canvas.Canvas{ID: "c1", AdminID: "admin", Width: 10, Height: 5, Status: canvas.StatusStart}
+	if err := store.SaveCanvas(ctx, c); err != nil {
+		t.Fatalf("SaveCanvas failed: %v", err)
+	}
+	if err := store.SetStatus(ctx, "c1", canvas.StatusPause); err != nil {
+		t.Fatalf("SetStatus failed: %v", err)
+	}
+
+	got, err := store.GetCanvas(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetCanvas failed: %v", err)
+	}
+	if got.Status != canvas.StatusPause || got.Width != 10 || got.Height != 5 {
+		t.Fatalf("unexpected canvas: %+v", got)
+	}
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c2", Status: canvas.StatusStart})
+	paused, err := store.ListCanvases(ctx, canvas.StatusPause)
+	if err != nil {
+		t.Fatalf("ListCanvases failed: %v", err)
+	}
+	if len(paused) != 1 || paused[0].ID != "c1" {
+		t.Fatalf("ListCanvases(PAUSE): got %+v, want c1 only", paused)
+	}
+}
+
+func TestMemoryStorePixels(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	var wg sync.WaitGroup
+	for x := 0; x < 8; x++ {
+		wg.Add(1)
+		go func(x int) {
+			defer wg.Done()
+			for y := 0; y < 8; y++ {
+				_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: x, Y: y, Color: "#000000"})
+			}
+		}(x)
+	}
+	wg.Wait()
+
+	if err := store.PutPixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#FF0000"}); err != nil {
+		t.Fatalf("PutPixel failed: %v", err)
+	}
+
+	pixels, err := store.ListPixels(ctx, "c1")
+	if err != nil {
+		t.Fatalf("ListPixels failed: %v", err)
+	}
+	if len(pixels) != 64 {
+		t.Fatalf("got %d pixels, want 64", len(pixels))
+	}
+	for _, p := range pixels {
+		if p.X == 0 && p.Y == 0 && p.Color != "#FF0000" {
+			t.Fatalf("pixel 0_0 was not overwritten: %+v", p)
+		}
+	}
+
+	if err := store.DeletePixels(ctx, "c1"); err != nil {
+		t.Fatalf("DeletePixels failed: %v", err)
+	}
+	pixels, _ = store.ListPixels(ctx, "c1")
+	if len(pixels) != 0 {
+		t.Fatalf("got %d pixels after delete, want 0", len(pixels))
+	}
+}
+
+func TestMemoryStoreRateLimit(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	if _, err := store.LastPixelTime(ctx, "c1", "user"); !errors.Is(err, canvas.ErrNotFound) {
+		t.Fatalf("LastPixelTime on new user: got %v, want ErrNotFound", err)
+	}
+
+	now := time.Now()
+	if err := store.SetLastPixelTime(ctx, "c1", "user", now); err != nil {
+		t.Fatalf("SetLastPixelTime failed: %v", err)
+	}
+	got, err := store.LastPixelTime(ctx, "c1", "user")
+	if err != nil || !got.Equal(now) {
+		t.Fatalf("LastPixelTime: got %v, %v, want %v", got, err, now)
+	}
+}
+
+func TestMemoryStorePlacePixelCooldown(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	placed := 0
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: i, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{Interval: 35 * time.Second, Burst: 1})
+			var cooldown *canvas.CooldownError
+			switch {
+			case err == nil:
+				mu.Lock()
+				placed++
+				mu.Unlock()
+			case !errors.As(err, &cooldown):
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if placed != 1 {
+		t.Fatalf("placed %d pixels concurrently, want 1", placed)
+	}
+
+	err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: 0, Y: 1, AuthorID: "user", UpdatedAt: now.Add(10 * time.Second)}, canvas.Cooldown{Interval: 35 * time.Second, Burst: 1})
+	var cooldown *canvas.CooldownError
+	if !errors.As(err, &cooldown) || cooldown.Remaining != 25*time.Second {
+		t.Fatalf("got %v, want 25s cooldown", err)
+	}
+
+	if err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: 0, Y: 1, AuthorID: "user", UpdatedAt: now.Add(35 * time.Second)}, canvas.Cooldown{Interval: 35 * time.Second, Burst: 1}); err != nil {
+		t.Fatalf("PlacePixel after cooldown failed: %v", err)
+	}
+}
+
+func TestMemoryStorePlacePixelNotRunning(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusPause})
+	err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{})
+	if !errors.Is(err, canvas.ErrNotRunning) {
+		t.Fatalf("PlacePixel on paused canvas: got %v, want ErrNotRunning", err)
+	}
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusStart, EndDate: now})
+	err = store.PlacePixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{})
+	if !errors.Is(err, canvas.ErrNotRunning) {
+		t.Fatalf("PlacePixel after the end date: got %v, want ErrNotRunning", err)
+	}
+
+	if pixels, _ := store.ListPixels(ctx, "c1"); len(pixels) != 0 {
+		t.Fatalf("got %d pixels, want none", len(pixels))
+	}
+}
+
+func TestMemoryStoreStopCanvas(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+
+	if _, err := store.StopCanvas(ctx, "missing", "admin", time.Now()); !errors.Is(err, canvas.ErrNotFound) {
+		t.Fatalf("StopCanvas on missing canvas: got %v, want ErrNotFound", err)
+	}
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusStart})
+	_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000"})
+	_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: 1, Y: 0, Color: "#000000"})
+
+	endedAt := time.Now()
+	c, err := store.StopCanvas(ctx, "c1", "admin", endedAt)
+	if err != nil {
+		t.Fatalf("StopCanvas failed: %v", err)
+	}
+	if c.Status != canvas.StatusStop || c.EndedBy != "admin" || !c.EndedAt.Equal(endedAt) || c.PixelCount != 2 {
+		t.Fatalf("unexpected canvas: %+v", c)
+	}
+
+	var transitionErr *canvas.TransitionError
+	if _, err := store.StopCanvas(ctx, "c1", "other", time.Now()); !errors.As(err, &transitionErr) {
+		t.Fatalf("second StopCanvas: got %v, want TransitionError", err)
+	}
+
+	// The same author resumes the stop until the final snapshot is requested.
+	c, err = store.StopCanvas(ctx, "c1", "admin", time.Now())
+	if err != nil || !c.EndedAt.Equal(endedAt) || c.PixelCount != 2 {
+		t.Fatalf("resumed StopCanvas: got %+v, %v", c, err)
+	}
+	_ = store.SetFinalSnapshotAt(ctx, "c1", time.Now())
+	if _, err := store.StopCanvas(ctx, "c1", "admin", time.Now()); !errors.As(err, &transitionErr) {
+		t.Fatalf("StopCanvas after final snapshot: got %v, want TransitionError", err)
+	}
+}
+
+func TestMemoryStoreListPixelsSince(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusStart})
+	_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: 0, Y: 0, Color: "#000000", UpdatedAt: now.Add(-time.Hour)})
+	_ = store.PutPixel(ctx, "c1", canvas.Pixel{X: 1, Y: 0, Color: "#FF0000", UpdatedAt: now})
+
+	pixels, err := store.ListPixelsSince(ctx, "c1", now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("ListPixelsSince failed: %v", err)
+	}
+	if len(pixels) != 1 || pixels[0].X != 1 {
+		t.Fatalf("got %+v, want only (1, 0)", pixels)
+	}
+
+	if err := store.DeletePixels(ctx, "c1"); err != nil {
+		t.Fatalf("DeletePixels failed: %v", err)
+	}
+	c, _ := store.GetCanvas(ctx, "c1")
+	if c.ResetAt.Before(now) {
+		t.Fatalf("ResetAt = %s, want it set by DeletePixels", c.ResetAt)
+	}
+}
+
+// TestMemoryStoreLayouts checks both layouts store and count the same
+// drawing.
+func TestMemoryStoreLayouts(t *testing.T) {
+	for _, layout := range []canvas.PixelLayout{canvas.LayoutPixels, canvas.LayoutChunks} {
+		t.Run(string(layout), func(t *testing.T) {
+			ctx := context.Background()
+			store := canvas.NewMemoryStore()
+			now := time.Now()
+
+			_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 128, Height: 128, Layout: layout, Status: canvas.StatusStart})
+			changed, err := store.PutPixels(ctx, "c1", []canvas.Pixel{
+				{X: 0, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now.Add(-time.Hour)},
+				{X: 100, Y: 100, Color: "#FF0000", AuthorID: "user", UpdatedAt: now.Add(-time.Hour)},
+				{X: 1, Y: 0, Color: canvas.DefaultColor, AuthorID: "user", UpdatedAt: now.Add(-time.Hour)},
+			})
+			if err != nil || changed != 2 {
+				t.Fatalf("PutPixels: got %d, %v, want 2 changed", changed, err)
+			}
+			if err := store.PlacePixel(ctx, "c1", canvas.Pixel{X: 100, Y: 100, Color: canvas.DefaultColor, AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{}); err != nil {
+				t.Fatalf("PlacePixel failed: %v", err)
+			}
+
+			history, _ := store.CoordinateHistory(ctx, "c1", 100, 100)
+			if len(history) != 2 || history[len(history)-1].PreviousColor != "#FF0000" {
+				t.Fatalf("got history %+v, want #FF0000 erased", history)
+			}
+
+			count, err := store.CountPixels(ctx, "c1")
+			if err != nil || count != 1 {
+				t.Fatalf("CountPixels: got %d, %v, want 1", count, err)
+			}
+			pixels, _ := store.ListPixels(ctx, "c1")
+			drawn := 0
+			for _, p := range pixels {
+				if p.Color != canvas.DefaultColor {
+					drawn++
+					if p.X != 0 || p.Y != 0 || p.Color != "#000000" {
+						t.Fatalf("unexpected pixel %+v", p)
+					}
+				}
+			}
+			if drawn != 1 {
+				t.Fatalf("got %d drawn pixels, want 1", drawn)
+			}
+
+			since, _ := store.ListPixelsSince(ctx, "c1", now.Add(-time.Minute))
+			found := false
+			for _, p := range since {
+				if p.X == 100 && p.Y == 100 {
+					found = p.Color == canvas.DefaultColor
+				}
+			}
+			if !found {
+				t.Fatalf("ListPixelsSince misses the erased pixel: %+v", since)
+			}
+
+			c, err := store.StopCanvas(ctx, "c1", "admin", now)
+			if err != nil || c.PixelCount != 1 {
+				t.Fatalf("StopCanvas: got %+v, %v, want 1 pixel", c, err)
+			}
+
+			_ = store.DeletePixels(ctx, "c1")
+			if count, _ := store.CountPixels(ctx, "c1"); count != 0 {
+				t.Fatalf("got %d pixels after delete, want 0", count)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreDeleteSession(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Now()
+
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1", Width: 4, Height: 4, Status: canvas.StatusStart})
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c2", Width: 4, Height: 4, Status: canvas.StatusStart})
+	for _, id := range []string{"c1", "c2"} {
+		if err := store.PlacePixel(ctx, id, canvas.Pixel{X: 0, Y: 0, Color: "#000000", AuthorID: "user", UpdatedAt: now}, canvas.Cooldown{}); err != nil {
+			t.Fatalf("PlacePixel failed: %v", err)
+		}
+	}
+
+	if err := store.DeleteSession(ctx, "c1"); err != nil {
+		t.Fatalf("DeleteSession failed: %v", err)
+	}
+
+	if history, _ := store.AuthorHistory(ctx, "c1", "user"); len(history) != 0 {
+		t.Fatalf("got %d placements after DeleteSession, want 0", len(history))
+	}
+	if last, _ := store.LastPixelTime(ctx, "c1", "user"); !last.IsZero() {
+		t.Fatalf("got last pixel time %s after DeleteSession, want none", last)
+	}
+	if history, _ := store.AuthorHistory(ctx, "c2", "user"); len(history) != 1 {
+		t.Fatalf("DeleteSession cleared another canvas: got %d placements, want 1", len(history))
+	}
+}
@@ -0,0 +1,133 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Palette presets, named after their size.
+const (
+	Palette16 = "16"
+	Palette32 = "32"
+)
+
+// MaxPaletteSize keeps palette indexes in a byte, which is also the most a
+// GIF frame can hold.
+const MaxPaletteSize = 256
+
+// Palettes are the r/place color sets: 16 colors from 2017 and 32 from 2022.
+var Palettes = map[string][]string{
+	Palette16: {
+		"#FFFFFF", "#E4E4E4", "#888888", "#222222",
+		"#FFA7D1", "#E50000", "#E59500", "#A06A42",
+		"#E5D900", "#94E044", "#02BE01", "#00D3DD",
+		"#0083C7", "#0000EA", "#CF6EE4", "#820080",
+	},
+	Palette32: {
+		"#6D001A", "#BE0039", "#FF4500", "#FFA800",
+		"#FFD635", "#FFF8B8", "#00A368", "#00CC78",
+		"#7EED56", "#00756F", "#009EAA", "#00CCC0",
+		"#2450A4", "#3690EA", "#51E9F4", "#493AC1",
+		"#6A5CFF", "#94B3FF", "#811E9F", "#B44AC0",
+		"#E4ABFF", "#DE107F", "#FF3881", "#FF99AA",
+		"#6D482F", "#9C6926", "#FFB470", "#000000",
+		"#515252", "#898D90", "#D4D7D9", "#FFFFFF",
+	},
+}
+
+// paletteNames names the colors of each preset, keys are folded with
+// colorName. They take precedence over CSS names, so "red" is the r/place
+// red of the canvas palette.
+var paletteNames = map[string]map[string]string{
+	Palette16: {
+		"white": "#FFFFFF", "lightgray": "#E4E4E4", "gray": "#888888", "black": "#222222",
+		"pink": "#FFA7D1", "red": "#E50000", "orange": "#E59500", "brown": "#A06A42",
+		"yellow": "#E5D900", "lime": "#94E044", "green": "#02BE01", "cyan": "#00D3DD",
+		"blue": "#0083C7", "darkblue": "#0000EA", "magenta": "#CF6EE4", "purple": "#820080",
+	},
+	Palette32: {
+		"burgundy": "#6D001A", "darkred": "#BE0039", "red": "#FF4500", "orange": "#FFA800",
+		"yellow": "#FFD635", "paleyellow": "#FFF8B8", "darkgreen": "#00A368", "green": "#00CC78",
+		"lightgreen": "#7EED56", "darkteal": "#00756F", "teal": "#009EAA", "lightteal": "#00CCC0",
+		"darkblue": "#2450A4", "blue": "#3690EA", "lightblue": "#51E9F4", "indigo": "#493AC1",
+		"periwinkle": "#6A5CFF", "lavender": "#94B3FF", "darkpurple": "#811E9F", "purple": "#B44AC0",
+		"palepurple": "#E4ABFF", "magenta": "#DE107F", "pink": "#FF3881", "lightpink": "#FF99AA",
+		"darkbrown": "#6D482F", "brown": "#9C6926", "beige": "#FFB470", "black": "#000000",
+		"darkgray": "#515252", "gray": "#898D90", "lightgray": "#D4D7D9", "white": "#FFFFFF",
+	},
+}
+
+func isPaletteName(name string) bool {
+	for _, names := range paletteNames {
+		if _, ok := names[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPalette applies to canvases created without a palette.
+const DefaultPalette = Palette32
+
+// NewPalette validates a custom palette. Colors are normalized and
+// duplicates dropped.
+func NewPalette(colors []string) ([]string, error) {
+	palette := make([]string, 0, len(colors))
+	seen := make(map[string]bool, len(colors))
+	for _, color := range colors {
+		hex, err := NormalizeHex(color)
+		if err != nil {
+			return nil, err
+		}
+		if seen[hex] {
+			continue
+		}
+		seen[hex] = true
+		palette = append(palette, hex)
+	}
+
+	if len(palette) < 2 {
+		return nil, errors.New("a palette needs at least 2 colors")
+	}
+	if len(palette) > MaxPaletteSize {
+		return nil, fmt.Errorf("a palette holds at most %d colors", MaxPaletteSize)
+	}
+	return palette, nil
+}
+
+// PaletteColors returns the colors players can draw with.
+func (c *Canvas) PaletteColors() []string {
+	if len(c.Palette) == 0 {
+		return Palettes[DefaultPalette]
+	}
+	return c.Palette
+}
+
+// ColorIndex resolves a color written in any form NormalizeColor accepts
+// to its position in the canvas palette.
+func (c *Canvas) ColorIndex(color string) (int, bool) {
+	palette := c.PaletteColors()
+	text := strings.TrimSpace(color)
+
+	if index, ok := parseIndex(text); ok {
+		return index, index < len(palette)
+	}
+
+	if name := colorName(text); isPaletteName(name) {
+		for _, preset := range slices.Sorted(maps.Keys(paletteNames)) {
+			if i := slices.Index(palette, paletteNames[preset][name]); i >= 0 {
+				return i, true
+			}
+		}
+	}
+
+	hex, err := NormalizeHex(text)
+	if err != nil {
+		return 0, false
+	}
+	i := slices.Index(palette, hex)
+	return i, i >= 0
+}
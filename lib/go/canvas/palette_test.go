@@ -0,0 +1,51 @@
+package canvas_test
+
+import (
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestColorIndex(t *testing.T) {
+	c := canvas.Canvas{}
+
+	if i, ok := c.ColorIndex("#ff4500"); !ok || i != 2 {
+		t.Fatalf("default palette: got %d, %v, want 2", i, ok)
+	}
+	if _, ok := c.ColorIndex("#123456"); ok {
+		t.Fatal("color outside the palette accepted")
+	}
+	if _, ok := c.ColorIndex("not a color"); ok {
+		t.Fatal("invalid color accepted")
+	}
+	for _, color := range []string{"2", "red", "ff4500", "rgb(255, 69, 0)", "OrangeRed"} {
+		if i, ok := c.ColorIndex(color); !ok || i != 2 {
+			t.Errorf("ColorIndex(%q): got %d, %v, want 2", color, i, ok)
+		}
+	}
+	if _, ok := c.ColorIndex("32"); ok {
+		t.Fatal("index past the palette accepted")
+	}
+
+	palette, err := canvas.NewPalette([]string{"#000000", "#ffffff", "#FFFFFF"})
+	if err != nil {
+		t.Fatalf("NewPalette failed: %v", err)
+	}
+	c.Palette = palette
+	if i, ok := c.ColorIndex("#FFFFFF"); !ok || i != 1 || len(palette) != 2 {
+		t.Fatalf("custom palette: got %d, %v in %v", i, ok, palette)
+	}
+}
+
+func TestNewPaletteRejects(t *testing.T) {
+	for _, colors := range [][]string{
+		nil,
+		{"#000000"},
+		{"#000000", "#GGGGGG"},
+		{"#000000", "rgb(0, 0, 256)"},
+	} {
+		if _, err := canvas.NewPalette(colors); err == nil {
+			t.Errorf("NewPalette(%v) succeeded, want error", colors)
+		}
+	}
+}
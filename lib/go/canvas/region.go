@@ -0,0 +1,93 @@
+package canvas
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Region is a named rectangle locked by the canvas admin: only the admin
+// and moderators can draw inside it. Corners are included.
+type Region struct {
+	Name string `firestore:"Name" json:"name"`
+	X0   int    `firestore:"X0" json:"x0"`
+	Y0   int    `firestore:"Y0" json:"y0"`
+	X1   int    `firestore:"X1" json:"x1"`
+	Y1   int    `firestore:"Y1" json:"y1"`
+	// Zero means the region stays locked until removed.
+	ExpiresAt time.Time `firestore:"ExpiresAt,omitempty" json:"expiresAt,omitempty"`
+}
+
+// NewRegion validates a region against the canvas size. Corners can be
+// given in any order.
+func (c *Canvas) NewRegion(name string, x0, y0, x1, y1 int, expiresAt time.Time) (Region, error) {
+	if name == "" {
+		return Region{}, errors.New("a region needs a name")
+	}
+	if !c.InBounds(x0, y0) || !c.InBounds(x1, y1) {
+		return Region{}, fmt.Errorf("the region goes out of bounds, the canvas is %dx%d", c.Width, c.Height)
+	}
+	return Region{
+		Name:      name,
+		X0:        min(x0, x1),
+		Y0:        min(y0, y1),
+		X1:        max(x0, x1),
+		Y1:        max(y0, y1),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (r Region) Contains(x, y int) bool {
+	return x >= r.X0 && x <= r.X1 && y >= r.Y0 && y <= r.Y1
+}
+
+// ActiveAt reports whether the region is still locked at t.
+func (r Region) ActiveAt(t time.Time) bool {
+	return r.ExpiresAt.IsZero() || t.Before(r.ExpiresAt)
+}
+
+// ActiveRegions returns the regions still locked at t.
+func (c *Canvas) ActiveRegions(t time.Time) []Region {
+	var regions []Region
+	for _, r := range c.Regions {
+		if r.ActiveAt(t) {
+			regions = append(regions, r)
+		}
+	}
+	return regions
+}
+
+// LockedRegion returns the region locking (x, y) at t, if any.
+func (c *Canvas) LockedRegion(x, y int, t time.Time) (Region, bool) {
+	for _, r := range c.ActiveRegions(t) {
+		if r.Contains(x, y) {
+			return r, true
+		}
+	}
+	return Region{}, false
+}
+
+// CanBypassRegions reports whether the author may draw in locked regions.
+func (c *Canvas) CanBypassRegions(authorID string, moderator bool) bool {
+	return moderator || authorID == c.AdminID
+}
+
+// WithRegion returns the active regions at t with r added, replacing the
+// region of the same name.
+func (c *Canvas) WithRegion(r Region, t time.Time) []Region {
+	regions := slices.DeleteFunc(c.ActiveRegions(t), func(other Region) bool {
+		return other.Name == r.Name
+	})
+	return append(regions, r)
+}
+
+// WithoutRegion returns the active regions at t without the one named
+// name, and whether it was found.
+func (c *Canvas) WithoutRegion(name string, t time.Time) ([]Region, bool) {
+	regions := c.ActiveRegions(t)
+	kept := slices.DeleteFunc(regions, func(r Region) bool {
+		return r.Name == name
+	})
+	return kept, len(kept) < len(regions)
+}
@@ -0,0 +1,53 @@
+package canvas_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestLockedRegion(t *testing.T) {
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	c := &canvas.Canvas{AdminID: "admin", Width: 20, Height: 20}
+
+	if _, err := c.NewRegion("logo", 0, 0, 20, 5, time.Time{}); err == nil {
+		t.Fatal("region out of bounds accepted")
+	}
+
+	logo, err := c.NewRegion("logo", 9, 4, 5, 0, time.Time{})
+	if err != nil {
+		t.Fatalf("NewRegion failed: %v", err)
+	}
+	if logo.X0 != 5 || logo.Y0 != 0 || logo.X1 != 9 || logo.Y1 != 4 {
+		t.Fatalf("corners not ordered: %+v", logo)
+	}
+	banner, _ := c.NewRegion("banner", 0, 10, 19, 12, now.Add(time.Hour))
+	c.Regions = c.WithRegion(logo, now)
+	c.Regions = c.WithRegion(banner, now)
+
+	if r, ok := c.LockedRegion(5, 4, now); !ok || r.Name != "logo" {
+		t.Fatalf("(5, 4): got %+v, %v, want logo", r, ok)
+	}
+	if _, ok := c.LockedRegion(10, 4, now); ok {
+		t.Fatal("(10, 4) locked outside every region")
+	}
+	if _, ok := c.LockedRegion(0, 11, now.Add(time.Hour)); ok {
+		t.Fatal("banner still locked once expired")
+	}
+
+	if !c.CanBypassRegions("admin", false) || !c.CanBypassRegions("mod", true) || c.CanBypassRegions("player", false) {
+		t.Fatal("unexpected CanBypassRegions result")
+	}
+
+	regions, ok := c.WithoutRegion("logo", now)
+	if !ok || len(regions) != 1 || regions[0].Name != "banner" {
+		t.Fatalf("WithoutRegion(logo): got %+v, %v", regions, ok)
+	}
+	if _, ok := c.WithoutRegion("missing", now); ok {
+		t.Fatal("WithoutRegion found a missing region")
+	}
+	if regions := c.WithRegion(logo, now.Add(2*time.Hour)); len(regions) != 1 {
+		t.Fatalf("expired regions kept: %+v", regions)
+	}
+}
@@ -0,0 +1,41 @@
+package canvas
+
+// ColorsAt rewinds current, the pixels stored now, to the colors the
+// canvas had at some time t. placements is the history since t, oldest
+// first, as returned by HistoryBetween. Resets are not in the history, so t
+// must not be before the last one. Coordinates missing from the result have
+// DefaultColor.
+func ColorsAt(current []Pixel, placements []Placement) map[Point]string {
+	colors := make(map[Point]string, len(current))
+	for _, pixel := range current {
+		colors[Point{X: pixel.X, Y: pixel.Y}] = pixel.Color
+	}
+
+	// The oldest placement on a coordinate sets the color it had at t.
+	for i := len(placements) - 1; i >= 0; i-- {
+		p := placements[i]
+		colors[Point{X: p.X, Y: p.Y}] = p.PreviousColor
+	}
+	return colors
+}
+
+// RestorePixels returns the pixels bringing the rectangle with corners
+// (x0, y0) and (x1, y1) back to colors, with DefaultColor where colors has
+// none. The corners must be in bounds.
+func (c *Canvas) RestorePixels(colors map[Point]string, x0, y0, x1, y1 int) []Pixel {
+	points := Rect(x0, y0, x1, y1)
+	pixels := make([]Pixel, 0, len(points))
+	for _, p := range points {
+		color, ok := colors[p]
+		if !ok {
+			color = DefaultColor
+		}
+
+		pixel := Pixel{X: p.X, Y: p.Y, Color: color}
+		if index, ok := c.ColorIndex(color); ok {
+			pixel.ColorIndex = index
+		}
+		pixels = append(pixels, pixel)
+	}
+	return pixels
+}
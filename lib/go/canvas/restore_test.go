@@ -0,0 +1,61 @@
+package canvas_test
+
+import (
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestColorsAt(t *testing.T) {
+	current := []canvas.Pixel{
+		{X: 0, Y: 0, Color: "#FF0000"},
+		{X: 1, Y: 0, Color: "#000000"},
+		{X: 2, Y: 0, Color: "#0000FF"},
+	}
+	// (1, 0) was not touched since, (2, 0) was erased in between.
+	placements := []canvas.Placement{
+		{X: 0, Y: 0, Color: "#00FF00", PreviousColor: "#0000FF"},
+		{X: 2, Y: 0, Color: "#FF0000", PreviousColor: "#000000"},
+		{X: 0, Y: 0, Color: "#FF0000", PreviousColor: "#00FF00"},
+		{X: 2, Y: 0, Color: canvas.DefaultColor, PreviousColor: "#FF0000"},
+		{X: 2, Y: 0, Color: "#0000FF", PreviousColor: canvas.DefaultColor},
+	}
+
+	colors := canvas.ColorsAt(current, placements)
+
+	want := map[canvas.Point]string{
+		{X: 0, Y: 0}: "#0000FF",
+		{X: 1, Y: 0}: "#000000",
+		{X: 2, Y: 0}: "#000000",
+	}
+	for point, color := range want {
+		if colors[point] != color {
+			t.Fatalf("%v: got %s, want %s", point, colors[point], color)
+		}
+	}
+}
+
+func TestRestorePixels(t *testing.T) {
+	c := &canvas.Canvas{Width: 4, Height: 4, Palette: []string{"#FFFFFF", "#000000"}}
+	colors := map[canvas.Point]string{
+		{X: 1, Y: 1}: "#000000",
+		{X: 3, Y: 3}: "#000000",
+	}
+
+	pixels := c.RestorePixels(colors, 1, 1, 2, 2)
+	if len(pixels) != 4 {
+		t.Fatalf("got %d pixels, want 4", len(pixels))
+	}
+	for _, pixel := range pixels {
+		want := canvas.DefaultColor
+		if pixel.X == 1 && pixel.Y == 1 {
+			want = "#000000"
+		}
+		if pixel.Color != want {
+			t.Fatalf("pixel %+v: want %s", pixel, want)
+		}
+	}
+	if pixels[0].ColorIndex != 1 {
+		t.Fatalf("got index %d, want 1", pixels[0].ColorIndex)
+	}
+}
@@ -0,0 +1,65 @@
+package canvas
+
+import "time"
+
+// RollbackPixels returns the pixels that undo the placements authorID made
+// in [from, to). placements is the canvas history since from, oldest first.
+// Each coordinate gets the color it would have had without those
+// placements, so later placements by other authors are kept. Only pixels
+// whose color changes are returned, with X, Y, Color and ColorIndex set.
+func (c *Canvas) RollbackPixels(placements []Placement, authorID string, from, to time.Time) []Pixel {
+	undone := func(p Placement) bool {
+		return p.AuthorID == authorID && !p.PlacedAt.Before(from) && p.PlacedAt.Before(to)
+	}
+
+	touched := make(map[Point]bool)
+	for _, p := range placements {
+		if undone(p) {
+			touched[Point{X: p.X, Y: p.Y}] = true
+		}
+	}
+
+	type replay struct {
+		current, clean string
+	}
+	coords := make(map[Point]*replay)
+	var order []Point
+
+	for _, p := range placements {
+		point := Point{X: p.X, Y: p.Y}
+		if !touched[point] {
+			continue
+		}
+
+		r, ok := coords[point]
+		if !ok {
+			r = &replay{current: p.PreviousColor, clean: p.PreviousColor}
+			coords[point] = r
+			order = append(order, point)
+		}
+
+		// A previous color differing from the replayed one means the
+		// pixels were reset in between.
+		if p.PreviousColor != r.current {
+			r.current, r.clean = p.PreviousColor, p.PreviousColor
+		}
+		r.current = p.Color
+		if !undone(p) {
+			r.clean = p.Color
+		}
+	}
+
+	var pixels []Pixel
+	for _, point := range order {
+		r := coords[point]
+		if r.clean == r.current {
+			continue
+		}
+		pixel := Pixel{X: point.X, Y: point.Y, Color: r.clean}
+		if index, ok := c.ColorIndex(r.clean); ok {
+			pixel.ColorIndex = index
+		}
+		pixels = append(pixels, pixel)
+	}
+	return pixels
+}
@@ -0,0 +1,69 @@
+package canvas_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestRollbackPixels(t *testing.T) {
+	c := &canvas.Canvas{Palette: []string{"#FFFFFF", "#000000", "#FF0000", "#0000FF"}}
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	placements := []canvas.Placement{
+		// (0, 0): painted blue, griefed red: back to blue.
+		{X: 0, Y: 0, Color: "#0000FF", PreviousColor: "#FFFFFF", AuthorID: "artist", PlacedAt: at(0)},
+		{X: 0, Y: 0, Color: "#FF0000", PreviousColor: "#0000FF", AuthorID: "griefer", PlacedAt: at(10)},
+		// (1, 0): griefed, then repainted by someone else: kept.
+		{X: 1, Y: 0, Color: "#FF0000", PreviousColor: "#FFFFFF", AuthorID: "griefer", PlacedAt: at(11)},
+		{X: 1, Y: 0, Color: "#000000", PreviousColor: "#FF0000", AuthorID: "artist", PlacedAt: at(12)},
+		// (2, 0): griefed twice over a black pixel placed before the history.
+		{X: 2, Y: 0, Color: "#FF0000", PreviousColor: "#000000", AuthorID: "griefer", PlacedAt: at(13)},
+		{X: 2, Y: 0, Color: "#0000FF", PreviousColor: "#FF0000", AuthorID: "griefer", PlacedAt: at(14)},
+		// (3, 0): griefed outside the window: kept.
+		{X: 3, Y: 0, Color: "#FF0000", PreviousColor: "#FFFFFF", AuthorID: "griefer", PlacedAt: at(90)},
+		// (4, 0): griefed, then the canvas was reset and griefed again.
+		{X: 4, Y: 0, Color: "#000000", PreviousColor: "#0000FF", AuthorID: "griefer", PlacedAt: at(15)},
+		{X: 4, Y: 0, Color: "#FF0000", PreviousColor: "#FFFFFF", AuthorID: "griefer", PlacedAt: at(16)},
+	}
+
+	got := c.RollbackPixels(placements, "griefer", at(5), at(60))
+
+	want := map[canvas.Point]string{
+		{X: 0, Y: 0}: "#0000FF",
+		{X: 2, Y: 0}: "#000000",
+		{X: 4, Y: 0}: "#FFFFFF",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d pixels, want %d: %+v", len(got), len(want), got)
+	}
+	for _, pixel := range got {
+		color, ok := want[canvas.Point{X: pixel.X, Y: pixel.Y}]
+		if !ok || pixel.Color != color {
+			t.Fatalf("unexpected pixel %+v", pixel)
+		}
+		if index, _ := c.ColorIndex(color); pixel.ColorIndex != index {
+			t.Fatalf("pixel %+v: got index %d, want %d", pixel, pixel.ColorIndex, index)
+		}
+	}
+}
+
+func TestMemoryStoreBanAuthor(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	_ = store.SaveCanvas(ctx, &canvas.Canvas{ID: "c1"})
+
+	for range 2 {
+		if err := store.BanAuthor(ctx, "c1", "griefer"); err != nil {
+			t.Fatalf("BanAuthor failed: %v", err)
+		}
+	}
+
+	c, _ := store.GetCanvas(ctx, "c1")
+	if !c.IsBanned("griefer") || c.IsBanned("artist") || len(c.Banned) != 1 {
+		t.Fatalf("unexpected ban list: %v", c.Banned)
+	}
+}
@@ -0,0 +1,98 @@
+package canvas
+
+// Shape is an admin drawing tool, written in bulk with Store.PutPixels.
+type Shape string
+
+const (
+	ShapeRect Shape = "rect"
+	ShapeLine Shape = "line"
+	ShapeFill Shape = "fill"
+)
+
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Rect returns the points of the filled rectangle with opposite corners
+// (x0, y0) and (x1, y1), both included.
+func Rect(x0, y0, x1, y1 int) []Point {
+	minX, maxX := min(x0, x1), max(x0, x1)
+	minY, maxY := min(y0, y1), max(y0, y1)
+
+	points := make([]Point, 0, (maxX-minX+1)*(maxY-minY+1))
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			points = append(points, Point{X: x, Y: y})
+		}
+	}
+	return points
+}
+
+// Line returns the points of the segment from (x0, y0) to (x1, y1), both
+// included, using Bresenham's algorithm.
+func Line(x0, y0, x1, y1 int) []Point {
+	dx, sx := x1-x0, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	dy, sy := y1-y0, 1
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+
+	points := make([]Point, 0, max(dx, dy)+1)
+	err := dx - dy
+	for x, y := x0, y0; ; {
+		points = append(points, Point{X: x, Y: y})
+		if x == x1 && y == y1 {
+			return points
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// FloodFill returns the region of the canvas reachable from (x, y) through
+// edges, where every point has the color of (x, y). Points missing from
+// pixels have DefaultColor. The start point must be in bounds.
+func (c *Canvas) FloodFill(pixels []Pixel, x, y int) []Point {
+	colors := make(map[Point]string, len(pixels))
+	for _, pixel := range pixels {
+		colors[Point{X: pixel.X, Y: pixel.Y}] = pixel.Color
+	}
+	colorAt := func(p Point) string {
+		if color, ok := colors[p]; ok {
+			return color
+		}
+		return DefaultColor
+	}
+
+	start := Point{X: x, Y: y}
+	target := colorAt(start)
+
+	visited := map[Point]bool{start: true}
+	queue := []Point{start}
+	var region []Point
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		region = append(region, p)
+
+		for _, next := range []Point{{p.X + 1, p.Y}, {p.X - 1, p.Y}, {p.X, p.Y + 1}, {p.X, p.Y - 1}} {
+			if visited[next] || !c.InBounds(next.X, next.Y) || colorAt(next) != target {
+				continue
+			}
+			visited[next] = true
+			queue = append(queue, next)
+		}
+	}
+	return region
+}
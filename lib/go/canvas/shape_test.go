@@ -0,0 +1,95 @@
+package canvas_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestRect(t *testing.T) {
+	points := canvas.Rect(2, 3, 0, 2)
+	if len(points) != 6 {
+		t.Fatalf("got %d points, want 6: %v", len(points), points)
+	}
+	for _, p := range points {
+		if p.X < 0 || p.X > 2 || p.Y < 2 || p.Y > 3 {
+			t.Fatalf("point %v outside the rectangle", p)
+		}
+	}
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		name           string
+		x0, y0, x1, y1 int
+		want           []canvas.Point
+	}{
+		{"single point", 1, 1, 1, 1, []canvas.Point{{X: 1, Y: 1}}},
+		{"horizontal", 3, 0, 0, 0, []canvas.Point{{X: 3, Y: 0}, {X: 2, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 0}}},
+		{"diagonal", 0, 0, 2, 2, []canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}}},
+		{"shallow", 0, 0, 4, 1, []canvas.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 1}, {X: 4, Y: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := canvas.Line(tt.x0, tt.y0, tt.x1, tt.y1)
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	c := &canvas.Canvas{Width: 4, Height: 4}
+	// A black wall on x = 1 splits the canvas, with a gap at y = 3.
+	pixels := []canvas.Pixel{
+		{X: 1, Y: 0, Color: "#000000"},
+		{X: 1, Y: 1, Color: "#000000"},
+		{X: 1, Y: 2, Color: "#000000"},
+	}
+
+	if got := c.FloodFill(pixels, 0, 0); len(got) != 13 {
+		t.Fatalf("white region: got %d points, want 13", len(got))
+	}
+	if got := c.FloodFill(pixels, 1, 1); len(got) != 3 {
+		t.Fatalf("wall: got %d points, want 3", len(got))
+	}
+
+	pixels = append(pixels, canvas.Pixel{X: 1, Y: 3, Color: "#000000"})
+	if got := c.FloodFill(pixels, 0, 0); len(got) != 4 {
+		t.Fatalf("closed region: got %d points, want 4", len(got))
+	}
+}
+
+func TestMemoryStorePutPixels(t *testing.T) {
+	ctx := context.Background()
+	store := canvas.NewMemoryStore()
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	var pixels []canvas.Pixel
+	for _, p := range canvas.Rect(0, 0, 1, 1) {
+		pixels = append(pixels, canvas.Pixel{X: p.X, Y: p.Y, Color: "#000000", AuthorID: "admin", UpdatedAt: now})
+	}
+	pixels[0].Color = canvas.DefaultColor
+
+	changed, err := store.PutPixels(ctx, "c1", pixels)
+	if err != nil {
+		t.Fatalf("PutPixels failed: %v", err)
+	}
+	if changed != 3 {
+		t.Fatalf("got %d pixels changed, want 3", changed)
+	}
+
+	history, _ := store.AuthorHistory(ctx, "c1", "admin")
+	if len(history) != 3 || history[0].PreviousColor != canvas.DefaultColor {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	if changed, _ := store.PutPixels(ctx, "c1", pixels); changed != 0 {
+		t.Fatalf("repeated PutPixels changed %d pixels, want 0", changed)
+	}
+}
@@ -0,0 +1,77 @@
+package canvas
+
+import (
+	"errors"
+	"image"
+	"image/color"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxStampSide bounds the images decoded for a stamp, before scaling.
+const MaxStampSide = 4096
+
+// StampPixels converts img to pixels of the canvas palette with its top
+// left corner at (x, y). The image is scaled to width pixels wide, or kept
+// at its size when width is zero, then shrunk to fit in the canvas; the
+// aspect ratio is kept. Each pixel gets the nearest palette color, with
+// Floyd-Steinberg error diffusion when dither is set. Mostly transparent
+// pixels are left out so the canvas shows through.
+func (c *Canvas) StampPixels(img image.Image, x, y, width int, dither bool) ([]Pixel, error) {
+	if !c.InBounds(x, y) {
+		return nil, errors.New("the stamp starts out of bounds")
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil, errors.New("the image is empty")
+	}
+	if width > 0 {
+		w, h = width, h*width/w
+	}
+	if maxW := c.Width - x; w > maxW {
+		w, h = maxW, h*maxW/w
+	}
+	if maxH := c.Height - y; h > maxH {
+		w, h = w*maxH/h, maxH
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+
+	colors := c.PaletteColors()
+	palette := make(color.Palette, len(colors))
+	for i, hex := range colors {
+		col, err := ParseColor(hex)
+		if err != nil {
+			return nil, err
+		}
+		palette[i] = col
+	}
+
+	var drawer draw.Drawer = draw.Src
+	if dither {
+		drawer = draw.FloydSteinberg
+	}
+	quantized := image.NewPaletted(scaled.Bounds(), palette)
+	drawer.Draw(quantized, quantized.Bounds(), scaled, image.Point{})
+
+	pixels := make([]Pixel, 0, w*h)
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			if scaled.RGBAAt(px, py).A < 0x80 {
+				continue
+			}
+			index := int(quantized.ColorIndexAt(px, py))
+			pixels = append(pixels, Pixel{
+				X:          x + px,
+				Y:          y + py,
+				Color:      colors[index],
+				ColorIndex: index,
+			})
+		}
+	}
+	return pixels, nil
+}
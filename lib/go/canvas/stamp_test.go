@@ -0,0 +1,53 @@
+package canvas_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func TestStampPixels(t *testing.T) {
+	c := &canvas.Canvas{Width: 10, Height: 10, Palette: []string{"#000000", "#FFFFFF", "#FF0000"}}
+
+	// Left half dark red, right half near white, bottom row transparent.
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			col := color.RGBA{R: 0xD0, G: 0x10, B: 0x10, A: 0xFF}
+			if x >= 2 {
+				col = color.RGBA{R: 0xF0, G: 0xF0, B: 0xF0, A: 0xFF}
+			}
+			img.SetRGBA(x, y, col)
+		}
+	}
+
+	pixels, err := c.StampPixels(img, 8, 1, 0, false)
+	if err != nil {
+		t.Fatalf("StampPixels failed: %v", err)
+	}
+
+	// The image is shrunk from 4x3 to 2x1 to fit after x = 8.
+	if len(pixels) != 2 {
+		t.Fatalf("got %d pixels, want 2: %+v", len(pixels), pixels)
+	}
+	if pixels[0].X != 8 || pixels[0].Y != 1 || pixels[0].Color != "#FF0000" || pixels[0].ColorIndex != 2 {
+		t.Fatalf("unexpected left pixel: %+v", pixels[0])
+	}
+	if pixels[1].X != 9 || pixels[1].Color != "#FFFFFF" {
+		t.Fatalf("unexpected right pixel: %+v", pixels[1])
+	}
+
+	pixels, err = c.StampPixels(img, 0, 0, 0, true)
+	if err != nil {
+		t.Fatalf("StampPixels with dithering failed: %v", err)
+	}
+	if len(pixels) != 8 {
+		t.Fatalf("got %d pixels, want the 8 opaque ones", len(pixels))
+	}
+
+	if _, err := c.StampPixels(img, 10, 0, 0, false); err == nil {
+		t.Fatal("stamp out of bounds accepted")
+	}
+}
@@ -0,0 +1,99 @@
+package canvas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNotFound = errors.New("canvas: not found")
+
+// ErrNotRunning is returned by PlacePixel when the canvas was paused, stopped
+// or left its drawing window before the pixel could be placed.
+var ErrNotRunning = errors.New("canvas: not running")
+
+// CooldownError is returned by PlacePixel when the author is still in their
+// cooldown window.
+type CooldownError struct {
+	Remaining time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return fmt.Sprintf("canvas: cooldown not finished, %s remaining", e.Remaining.Round(time.Second))
+}
+
+// Store is the persistence layer used by every function. FirestoreStore is
+// the production backend, MemoryStore is meant for tests and local runs.
+type Store interface {
+	GetCanvas(ctx context.Context, canvasID string) (*Canvas, error)
+	SaveCanvas(ctx context.Context, c *Canvas) error
+	ListCanvases(ctx context.Context, status CanvasStatus) ([]Canvas, error)
+	SetStatus(ctx context.Context, canvasID string, status CanvasStatus) error
+	SetCooldownPolicy(ctx context.Context, canvasID string, policy CooldownPolicy) error
+	SetPalette(ctx context.Context, canvasID string, palette []string) error
+	SetRegions(ctx context.Context, canvasID string, regions []Region) error
+	// BanAuthor adds authorID to the canvas ban list, banning twice is a
+	// no-op.
+	BanAuthor(ctx context.Context, canvasID string, authorID string) error
+	// StopCanvas moves the canvas to StatusStop and records who ended it,
+	// when, and how many pixels were painted, as CountPixels returns. It
+	// returns a TransitionError if the canvas cannot be stopped, unless
+	// endedBy stopped it and its final snapshot was not requested yet: the
+	// pixels are then counted again, so an interrupted stop can be retried.
+	StopCanvas(ctx context.Context, canvasID string, endedBy string, endedAt time.Time) (*Canvas, error)
+	// SetFinalSnapshotAt records when the final snapshot of the stopped
+	// canvas was requested.
+	SetFinalSnapshotAt(ctx context.Context, canvasID string, at time.Time) error
+
+	PutPixel(ctx context.Context, canvasID string, pixel Pixel) error
+	ListPixels(ctx context.Context, canvasID string) ([]Pixel, error)
+	// ListPixelsSince returns the pixels updated after since. With
+	// LayoutChunks it returns every pixel of the chunks updated after since,
+	// those never drawn with DefaultColor, so pixels drawn back to the
+	// default color are returned too.
+	ListPixelsSince(ctx context.Context, canvasID string, since time.Time) ([]Pixel, error)
+	// CountPixels returns the number of pixels not of DefaultColor, the
+	// same in every layout.
+	CountPixels(ctx context.Context, canvasID string) (int, error)
+	// DeletePixels clears the pixels and sets the canvas ResetAt, when the
+	// canvas exists.
+	DeletePixels(ctx context.Context, canvasID string) error
+	// DeleteSession deletes the history and rate limits of the canvas,
+	// before it is started again.
+	DeleteSession(ctx context.Context, canvasID string) error
+	// PutPixels writes pixels in bulk and appends them to the history,
+	// skipping those that already have their color. It applies no cooldown
+	// and is not atomic. It returns the number of pixels changed, leaving
+	// out those whose write failed, along with the failures.
+	PutPixels(ctx context.Context, canvasID string, pixels []Pixel) (int, error)
+
+	// PlacePixel atomically spends a token from the author's cooldown
+	// bucket, writes the pixel and appends it to the canvas history. A zero
+	// cooldown skips the check but still records the placement time. It
+	// returns ErrNotRunning if the canvas no longer accepts pixels.
+	PlacePixel(ctx context.Context, canvasID string, pixel Pixel, cooldown Cooldown) error
+
+	// History queries return placements oldest first. HistoryBetween
+	// includes from and excludes to.
+	CoordinateHistory(ctx context.Context, canvasID string, x, y int) ([]Placement, error)
+	AuthorHistory(ctx context.Context, canvasID string, authorID string) ([]Placement, error)
+	HistoryBetween(ctx context.Context, canvasID string, from, to time.Time) ([]Placement, error)
+
+	LastPixelTime(ctx context.Context, canvasID string, authorID string) (time.Time, error)
+	SetLastPixelTime(ctx context.Context, canvasID string, authorID string, t time.Time) error
+
+	// ClaimMessage records that consumer processes messageID and reports
+	// whether no claim made within window already exists. See ProcessOnce.
+	ClaimMessage(ctx context.Context, consumer, messageID string, now time.Time, window time.Duration) (bool, error)
+	// CompleteMessage extends the claim to window once the message was
+	// processed.
+	CompleteMessage(ctx context.Context, consumer, messageID string, now time.Time, window time.Duration) error
+	// ReleaseMessage drops the claim so the message is processed again.
+	ReleaseMessage(ctx context.Context, consumer, messageID string) error
+}
+
+var (
+	_ Store = (*FirestoreStore)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
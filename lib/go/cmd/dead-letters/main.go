@@ -0,0 +1,145 @@
+// Command dead-letters inspects and replays the messages the consumers
+// dead-lettered, see events.Consumer.
+//
+//	GOOGLE_CLOUD_PROJECT=... dead-letters -subscription dead-letters-sub list
+//	GOOGLE_CLOUD_PROJECT=... dead-letters -subscription dead-letters-sub [-consumer draw-pixel] replay
+//
+// list prints up to -max messages and leaves them in the queue. replay
+// publishes them again on their original topic, with their original
+// attributes, and acknowledges them.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+)
+
+var (
+	subscription = flag.String("subscription", "", "subscription of the dead-letter topic")
+	consumer     = flag.String("consumer", "", "only handle messages dead-lettered by this consumer")
+	maxMessages  = flag.Int("max", 10, "maximum number of messages to handle")
+	wait         = flag.Duration("wait", 10*time.Second, "stop after this long without messages")
+)
+
+// receive calls handle on up to -max messages matching -consumer, one at a
+// time. Messages it does not handle, and those handle does not acknowledge,
+// are only nacked once receiving stops so they are not redelivered to it.
+func receive(ctx context.Context, sub *pubsub.Subscriber, handle func(ctx context.Context, msg *pubsub.Message) bool) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var pending []*pubsub.Message
+	handled := 0
+	idle := time.AfterFunc(*wait, cancel)
+	defer idle.Stop()
+
+	err := sub.Receive(ctx, func(ctx context.Context, msg *pubsub.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		idle.Reset(*wait)
+
+		if handled >= *maxMessages || (*consumer != "" && msg.Attributes[events.AttrConsumer] != *consumer) {
+			pending = append(pending, msg)
+			return
+		}
+
+		if !handle(ctx, msg) {
+			pending = append(pending, msg)
+		}
+		handled++
+		if handled >= *maxMessages {
+			cancel()
+		}
+	})
+
+	for _, msg := range pending {
+		msg.Nack()
+	}
+	return err
+}
+
+func list(ctx context.Context, sub *pubsub.Subscriber) error {
+	return receive(ctx, sub, func(ctx context.Context, msg *pubsub.Message) bool {
+		fmt.Printf("%s %s/%s message %s (dead letter %s)\n  %s: %s\n  %s\n",
+			msg.Attributes[events.AttrFailedAt],
+			msg.Attributes[events.AttrTopic],
+			msg.Attributes[events.AttrConsumer],
+			msg.Attributes[events.AttrMessageID],
+			msg.ID,
+			msg.Attributes[events.AttrKind],
+			msg.Attributes[events.AttrReason],
+			msg.Data,
+		)
+		return false
+	})
+}
+
+func replay(ctx context.Context, client *pubsub.Client, sub *pubsub.Subscriber) error {
+	var errs []error
+	err := receive(ctx, sub, func(ctx context.Context, msg *pubsub.Message) bool {
+		topic, original, err := events.Replay(msg)
+		if err != nil {
+			slog.Error("Cannot replay message", "id", msg.ID, "error", err)
+			errs = append(errs, err)
+			return false
+		}
+
+		publisher := client.Publisher(topic)
+		defer publisher.Stop()
+
+		id, err := publisher.Publish(ctx, original).Get(ctx)
+		if err != nil {
+			slog.Error("Failed to replay message", "id", msg.ID, "topic", topic, "error", err)
+			errs = append(errs, err)
+			return false
+		}
+
+		slog.Info("Message replayed", "id", msg.ID, "topic", topic, "newId", id)
+		msg.Ack()
+		return true
+	})
+	return errors.Join(append(errs, err)...)
+}
+
+func run() error {
+	flag.Parse()
+	if *subscription == "" || flag.NArg() != 1 {
+		flag.Usage()
+		return errors.New("usage: dead-letters -subscription <name> list|replay")
+	}
+
+	ctx := context.Background()
+	client, err := pubsub.NewClient(ctx, os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	if err != nil {
+		return fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+	defer client.Close()
+
+	sub := client.Subscriber(*subscription)
+
+	switch flag.Arg(0) {
+	case "list":
+		return list(ctx, sub)
+	case "replay":
+		return replay(ctx, client, sub)
+	default:
+		return fmt.Errorf("unknown command %q, want list or replay", flag.Arg(0))
+	}
+}
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("Application error", "error", err)
+		os.Exit(1)
+	}
+}
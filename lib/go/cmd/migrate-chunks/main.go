@@ -0,0 +1,72 @@
+// Command migrate-chunks moves canvases from one Firestore document per pixel
+// to chunk documents, see canvas.LayoutChunks.
+//
+//	GOOGLE_CLOUD_PROJECT=... FIRESTORE_DB=... migrate-chunks [canvasID...]
+//
+// Without arguments every paused or stopped canvas is migrated. Running and
+// scheduled canvases are skipped; pause them first.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/canvas"
+)
+
+func canvasIDs(ctx context.Context, store *canvas.FirestoreStore) ([]string, error) {
+	if len(os.Args) > 1 {
+		return os.Args[1:], nil
+	}
+
+	var ids []string
+	for _, st := range []canvas.CanvasStatus{canvas.StatusPause, canvas.StatusStop} {
+		canvases, err := store.ListCanvases(ctx, st)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s canvases: %w", st, err)
+		}
+		for _, c := range canvases {
+			if c.Layout != canvas.LayoutChunks {
+				ids = append(ids, c.ID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func run() error {
+	ctx := context.Background()
+
+	store, err := canvas.NewFirestoreStore(ctx, os.Getenv("GOOGLE_CLOUD_PROJECT"), os.Getenv("FIRESTORE_DB"))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ids, err := canvasIDs(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		moved, err := store.MigrateToChunks(ctx, id)
+		if err != nil {
+			slog.Error("Failed to migrate canvas", "canvasId", id, "error", err)
+			errs = append(errs, fmt.Errorf("canvas %s: %w", id, err))
+			continue
+		}
+		slog.Info("Canvas migrated", "canvasId", id, "pixels", moved)
+	}
+	return errors.Join(errs...)
+}
+
+func main() {
+	if err := run(); err != nil {
+		slog.Error("Application error", "error", err)
+		os.Exit(1)
+	}
+}
@@ -10,6 +10,9 @@ import (
 )
 
 func ParseRequest(w http.ResponseWriter, r *http.Request) (discordgo.Interaction, error) {
+	if err := load(); err != nil {
+		return discordgo.Interaction{}, err
+	}
 	if !discordgo.VerifyInteraction(r, pubKey) {
 		return discordgo.Interaction{}, fmt.Errorf("invalid request signature")
 	}
@@ -4,31 +4,59 @@ import (
 	"context"
 	"crypto/ed25519"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"log/slog"
-	"os"
+	"sync"
 
 	"github.com/Evan-Lab/cloud-native/lib/go/secrets"
-	"github.com/Evan-Lab/cloud-native/lib/go/utils"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-var token string
-var pubKey ed25519.PublicKey
+var (
+	loadMu sync.Mutex
+	token  string
+	pubKey ed25519.PublicKey
+)
 
-func init() {
-	ctx := context.Background()
-	token = string(utils.Must(secrets.Secret(ctx, "DISCORD_BOT_TOKEN")))
-	keyHex := utils.Must(secrets.Secret(ctx, "DISCORD_PUBLIC_KEY"))
-	pubKey = utils.Must(hex.DecodeString(string(keyHex)))
+// load reads the Discord credentials from Secret Manager the first time
+// they are needed, so importing the package does not require them. A failed
+// read is tried again on the next call.
+func load() error {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	if token != "" {
+		return nil
+	}
 
-	if token == "" || pubKey == nil {
-		slog.ErrorContext(ctx, "DISCORD_BOT_TOKEN or DISCORD_PUBLIC_KEY not set in environment")
-		os.Exit(2)
+	ctx := context.Background()
+	tokenSecret, err := secrets.Secret(ctx, "DISCORD_BOT_TOKEN")
+	if err != nil {
+		return fmt.Errorf("DISCORD_BOT_TOKEN: %w", err)
+	}
+	keyHex, err := secrets.Secret(ctx, "DISCORD_PUBLIC_KEY")
+	if err != nil {
+		return fmt.Errorf("DISCORD_PUBLIC_KEY: %w", err)
+	}
+	key, err := hex.DecodeString(string(keyHex))
+	if err != nil {
+		return fmt.Errorf("DISCORD_PUBLIC_KEY: %w", err)
+	}
+	if len(tokenSecret) == 0 || len(key) == 0 {
+		return errors.New("DISCORD_BOT_TOKEN or DISCORD_PUBLIC_KEY is empty")
 	}
+
+	token, pubKey = string(tokenSecret), key
+	return nil
 }
 
 func Session() (*discordgo.Session, error) {
+	if err := load(); err != nil {
+		slog.Error("Can not load Discord credentials", "error", err)
+		return nil, err
+	}
 
 	s, err := discordgo.New("Bot " + token)
 	if err != nil {
@@ -0,0 +1,162 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+// Dead letters keep the data and attributes of the original message and add
+// the following attributes, so they can be inspected and replayed.
+const (
+	AttrConsumer  = "dead_letter_consumer"
+	AttrKind      = "dead_letter_kind"
+	AttrReason    = "dead_letter_reason"
+	AttrMessageID = "dead_letter_message_id"
+	AttrTopic     = "dead_letter_topic"
+	AttrFailedAt  = "dead_letter_failed_at"
+)
+
+// Transient failures are retried in place before being handed back to
+// Pub/Sub, whose subscription retry policy backs off further.
+const (
+	retryAttempts = 3
+	retryBackoff  = 250 * time.Millisecond
+)
+
+type MessagePublishedData struct {
+	Message PubSubMessage `json:"message"`
+}
+
+type PubSubMessage struct {
+	Data       []byte            `json:"data"`
+	Attributes map[string]string `json:"attributes"`
+	MessageID  string            `json:"messageId"`
+}
+
+// Handler is a Pub/Sub consumer as registered with the functions framework.
+type Handler func(ctx context.Context, e event.Event) error
+
+// Consumer wraps handle so transient failures are retried with backoff and
+// then returned for Pub/Sub to redeliver, while permanent ones are published
+// to the DEAD_LETTER_TOPIC and acknowledged. Without a dead-letter topic
+// permanent failures are only logged.
+func Consumer(name string, handle Handler) Handler {
+	return func(ctx context.Context, e event.Event) error {
+		err := Retry(ctx, retryAttempts, retryBackoff, func() error {
+			return handle(ctx, e)
+		})
+		if err == nil {
+			return nil
+		}
+
+		if !Permanent(err) {
+			slog.ErrorContext(ctx, "Transient failure, leaving the message to Pub/Sub", "consumer", name, "error", err)
+			return err
+		}
+
+		if err := deadLetter(ctx, name, e, err); err != nil {
+			slog.ErrorContext(ctx, "Failed to dead-letter message", "consumer", name, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// TopicFromSource returns the topic ID out of the source of a Pub/Sub
+// CloudEvent, //pubsub.googleapis.com/projects/{project}/topics/{topic}.
+func TopicFromSource(source string) string {
+	_, topic, ok := strings.Cut(source, "/topics/")
+	if !ok {
+		return ""
+	}
+	return topic
+}
+
+var (
+	pubsubClient    *pubsub.Client
+	pubsubClientErr error
+	pubsubOnce      sync.Once
+)
+
+func client() (*pubsub.Client, error) {
+	pubsubOnce.Do(func() {
+		pubsubClient, pubsubClientErr = pubsub.NewClient(context.Background(), os.Getenv("GOOGLE_CLOUD_PROJECT"))
+		if pubsubClientErr != nil {
+			pubsubClientErr = fmt.Errorf("failed to create Pub/Sub client: %w", pubsubClientErr)
+		}
+	})
+	return pubsubClient, pubsubClientErr
+}
+
+// DeadLetterMessage builds the dead letter of the message carried by e.
+// Events that are not Pub/Sub messages keep their raw data.
+func DeadLetterMessage(consumer string, e event.Event, cause error, failedAt time.Time) *pubsub.Message {
+	var payload MessagePublishedData
+	if err := json.Unmarshal(e.Data(), &payload); err != nil {
+		payload.Message.Data = e.Data()
+	}
+
+	attributes := make(map[string]string, len(payload.Message.Attributes)+6)
+	maps.Copy(attributes, payload.Message.Attributes)
+	attributes[AttrConsumer] = consumer
+	attributes[AttrKind] = string(KindOf(cause))
+	attributes[AttrReason] = cause.Error()
+	attributes[AttrMessageID] = payload.Message.MessageID
+	attributes[AttrTopic] = TopicFromSource(e.Source())
+	attributes[AttrFailedAt] = failedAt.UTC().Format(time.RFC3339)
+
+	return &pubsub.Message{Data: payload.Message.Data, Attributes: attributes}
+}
+
+// Replay returns the message a dead letter was made from, to publish again
+// on its topic.
+func Replay(deadLetter *pubsub.Message) (topic string, msg *pubsub.Message, err error) {
+	topic = deadLetter.Attributes[AttrTopic]
+	if topic == "" {
+		return "", nil, errors.New("dead letter has no original topic")
+	}
+
+	attributes := make(map[string]string, len(deadLetter.Attributes))
+	for key, value := range deadLetter.Attributes {
+		if !strings.HasPrefix(key, "dead_letter_") {
+			attributes[key] = value
+		}
+	}
+	return topic, &pubsub.Message{Data: deadLetter.Data, Attributes: attributes}, nil
+}
+
+func deadLetter(ctx context.Context, consumer string, e event.Event, cause error) error {
+	msg := DeadLetterMessage(consumer, e, cause, time.Now())
+
+	topic := os.Getenv("DEAD_LETTER_TOPIC")
+	if topic == "" {
+		slog.WarnContext(ctx, "Dropping message, DEAD_LETTER_TOPIC not set", "consumer", consumer, "kind", msg.Attributes[AttrKind], "reason", msg.Attributes[AttrReason], "message_id", msg.Attributes[AttrMessageID])
+		return nil
+	}
+
+	c, err := client()
+	if err != nil {
+		return errors.Join(cause, err)
+	}
+
+	publisher := c.Publisher(topic)
+	defer publisher.Stop()
+
+	if _, err := publisher.Publish(ctx, msg).Get(ctx); err != nil {
+		return errors.Join(cause, err)
+	}
+
+	slog.WarnContext(ctx, "Message dead-lettered", "consumer", consumer, "kind", msg.Attributes[AttrKind], "reason", msg.Attributes[AttrReason], "message_id", msg.Attributes[AttrMessageID])
+	return nil
+}
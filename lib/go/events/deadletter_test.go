@@ -0,0 +1,67 @@
+package events_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+	"github.com/cloudevents/sdk-go/v2/event"
+)
+
+func TestDeadLetterRoundTrip(t *testing.T) {
+	e := event.New()
+	e.SetSource("//pubsub.googleapis.com/projects/demo/topics/drawing-pixel")
+	payload := events.MessagePublishedData{Message: events.PubSubMessage{
+		Data:       []byte(`{"x":1}`),
+		Attributes: map[string]string{"discord_interaction_token": "token"},
+		MessageID:  "42",
+	}}
+	if err := e.SetData(event.ApplicationJSON, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	failedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	msg := events.DeadLetterMessage("draw-pixel", e, events.Invalid(errors.New("missing required fields")), failedAt)
+
+	want := map[string]string{
+		"discord_interaction_token": "token",
+		events.AttrConsumer:         "draw-pixel",
+		events.AttrKind:             string(events.KindInvalid),
+		events.AttrReason:           "invalid_input: missing required fields",
+		events.AttrMessageID:        "42",
+		events.AttrTopic:            "drawing-pixel",
+		events.AttrFailedAt:         "2025-01-01T12:00:00Z",
+	}
+	for key, value := range want {
+		if msg.Attributes[key] != value {
+			t.Fatalf("attribute %s = %q, want %q", key, msg.Attributes[key], value)
+		}
+	}
+	if string(msg.Data) != `{"x":1}` {
+		t.Fatalf("data = %s, want the original message data", msg.Data)
+	}
+
+	topic, original, err := events.Replay(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topic != "drawing-pixel" {
+		t.Fatalf("topic = %s, want drawing-pixel", topic)
+	}
+	if len(original.Attributes) != 1 || original.Attributes["discord_interaction_token"] != "token" {
+		t.Fatalf("replayed attributes = %v, want the original ones", original.Attributes)
+	}
+	if string(original.Data) != `{"x":1}` {
+		t.Fatalf("replayed data = %s, want the original message data", original.Data)
+	}
+}
+
+func TestTopicFromSource(t *testing.T) {
+	if got := events.TopicFromSource("//pubsub.googleapis.com/projects/demo/topics/session-events"); got != "session-events" {
+		t.Fatalf("got %q, want session-events", got)
+	}
+	if got := events.TopicFromSource("//storage.googleapis.com/buckets/demo"); got != "" {
+		t.Fatalf("got %q, want no topic", got)
+	}
+}
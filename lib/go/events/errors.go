@@ -0,0 +1,89 @@
+// Package events holds what the Pub/Sub consumers share about failures:
+// how errors are classified, retried and dead-lettered.
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kind tells what should become of a message whose processing failed.
+type Kind string
+
+const (
+	// KindInvalid messages cannot be decoded or miss required fields, they
+	// will never succeed.
+	KindInvalid Kind = "invalid_input"
+	// KindRejected messages were refused by a canvas rule, such as an
+	// illegal lifecycle transition.
+	KindRejected Kind = "rejected"
+	// KindTransient failures come from the infrastructure, Firestore,
+	// Storage or Discord being unavailable, and are worth retrying.
+	KindTransient Kind = "transient"
+)
+
+// Error attaches a Kind to an error.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func Invalid(err error) error {
+	return &Error{Kind: KindInvalid, Err: err}
+}
+
+func Rejected(err error) error {
+	return &Error{Kind: KindRejected, Err: err}
+}
+
+func Transient(err error) error {
+	return &Error{Kind: KindTransient, Err: err}
+}
+
+// KindOf classifies err. Errors not wrapped in an Error are transient, so
+// unexpected failures keep being retried as before.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return KindTransient
+}
+
+// Permanent reports whether retrying err is pointless.
+func Permanent(err error) bool {
+	kind := KindOf(err)
+	return kind == KindInvalid || kind == KindRejected
+}
+
+// Retry runs f up to attempts times while it fails with transient errors,
+// waiting backoff, then twice as long, and so on between attempts. It
+// returns the last error.
+func Retry(ctx context.Context, attempts int, backoff time.Duration, f func() error) error {
+	var err error
+	for attempt := range attempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return errors.Join(err, ctx.Err())
+			case <-time.After(backoff << (attempt - 1)):
+			}
+		}
+
+		err = f()
+		if err == nil || Permanent(err) {
+			return err
+		}
+	}
+	return err
+}
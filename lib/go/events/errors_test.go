@@ -0,0 +1,59 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Evan-Lab/cloud-native/lib/go/events"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		err       error
+		want      events.Kind
+		permanent bool
+	}{
+		{err: events.Invalid(errors.New("bad json")), want: events.KindInvalid, permanent: true},
+		{err: fmt.Errorf("handle: %w", events.Rejected(errors.New("canvas stopped"))), want: events.KindRejected, permanent: true},
+		{err: events.Transient(errors.New("unavailable")), want: events.KindTransient},
+		{err: errors.New("deadline exceeded"), want: events.KindTransient},
+	}
+	for _, tt := range tests {
+		if got := events.KindOf(tt.err); got != tt.want {
+			t.Fatalf("KindOf(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+		if got := events.Permanent(tt.err); got != tt.permanent {
+			t.Fatalf("Permanent(%v) = %v, want %v", tt.err, got, tt.permanent)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := events.Retry(ctx, 3, 0, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("got %v after %d calls, want success after 3", err, calls)
+	}
+
+	calls = 0
+	invalid := events.Invalid(errors.New("bad json"))
+	if err := events.Retry(ctx, 3, 0, func() error { calls++; return invalid }); !errors.Is(err, invalid) || calls != 1 {
+		t.Fatalf("got %v after %d calls, want the permanent error after 1", err, calls)
+	}
+
+	calls = 0
+	unavailable := errors.New("unavailable")
+	if err := events.Retry(ctx, 2, 0, func() error { calls++; return unavailable }); !errors.Is(err, unavailable) || calls != 2 {
+		t.Fatalf("got %v after %d calls, want the last error after 2", err, calls)
+	}
+}
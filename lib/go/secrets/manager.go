@@ -2,6 +2,7 @@ package secrets
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"os"
 
@@ -16,12 +17,15 @@ var secretManagerID string
 func init() {
 	_ = godotenv.Load()
 	secretManagerID = os.Getenv("SECRET_MANAGER_ID")
-	if secretManagerID == "" {
-		panic("SECRET_MANAGER_ID not set in environment")
-	}
 }
 
+// Secret reads the latest version of a secret. SECRET_MANAGER_ID is checked
+// here rather than at init, so packages importing this one load without it.
 func Secret(ctx context.Context, secret string) ([]byte, error) {
+	if secretManagerID == "" {
+		return nil, errors.New("SECRET_MANAGER_ID not set in environment")
+	}
+
 	client, err := secretmanager.NewClient(ctx)
 	if err != nil {
 		return nil, err